@@ -1,36 +1,35 @@
 package main
 
 import (
+	"errors"
 	"github.com/shoriwe/gplasma/pkg/compiler"
 	"github.com/shoriwe/gplasma/pkg/vm"
 	"os"
 )
 
-func executeFiles() {
-	for _, arg := range os.Args {
-		switch arg {
-		case "-h", "--help":
-			help()
-		}
-	}
-	files := make([][]byte, 0, len(os.Args[1:]))
-	for _, file := range os.Args[1:] {
+func executeFiles(paths []string) {
+	files := make([][]byte, 0, len(paths))
+	for _, file := range paths {
 		contents, readError := os.ReadFile(file)
 		if readError != nil {
 			onError(file, readError)
 		}
 		files = append(files, contents)
 	}
-	plasma := vm.NewVM(os.Stdin, os.Stdout, os.Stderr)
+	plasma := newVM()
 	for index, file := range files {
 		bytecode, compileError := compiler.Compile(string(file))
 		if compileError != nil {
-			onError(os.Args[1:][index], compileError)
+			onError(paths[index], compileError)
 		}
 		_, errorChan, _ := plasma.Execute(bytecode)
 		executeError := <-errorChan
+		var exitError *vm.ExitError
+		if errors.As(executeError, &exitError) {
+			os.Exit(exitError.Code)
+		}
 		if executeError != nil {
-			onError(os.Args[1:][index], executeError)
+			onError(paths[index], executeError)
 		}
 	}
 }
@@ -5,9 +5,12 @@ import (
 	"os"
 )
 
-const helpMessage = `Usage: %s [FILE [FILE [FILE [...]]]]
+const helpMessage = `Usage: %s [--allow-os] [FILE [FILE [FILE [...]]]]
 
-Zero arguments will start the REPL'`
+Zero files will start the REPL
+--allow-os gives the scripts access to the environment and the processes of the host,
+os.args() returns the arguments of this command
+`
 
 func help() {
 	fmt.Printf(helpMessage, os.Args[0])
@@ -1,13 +1,36 @@
 package main
 
 import (
+	"github.com/shoriwe/gplasma/pkg/vm"
 	"os"
 )
 
+// allowOS is set with --allow-os, only then scripts reach the environment and the processes of the host
+var allowOS bool
+
+func newVM() *vm.Plasma {
+	plasma := vm.NewVM(os.Stdin, os.Stdout, os.Stderr)
+	if allowOS {
+		plasma.SetOS(vm.HostOS(os.Args))
+	}
+	return plasma
+}
+
 func main() {
-	if len(os.Args) == 1 {
+	files := make([]string, 0, len(os.Args))
+	for _, arg := range os.Args[1:] {
+		switch arg {
+		case "-h", "--help":
+			help()
+		case "--allow-os":
+			allowOS = true
+		default:
+			files = append(files, arg)
+		}
+	}
+	if len(files) == 0 {
 		repl()
-	} else if len(os.Args) > 1 {
-		executeFiles()
+	} else {
+		executeFiles(files)
 	}
 }
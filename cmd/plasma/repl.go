@@ -2,8 +2,10 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"github.com/fatih/color"
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
 	"github.com/shoriwe/gplasma/pkg/compiler"
 	"github.com/shoriwe/gplasma/pkg/vm"
 	"os"
@@ -38,17 +40,23 @@ func repl() {
 			os.Exit(1)
 		}
 	}()
-	plasma := vm.NewVM(os.Stdin, os.Stdout, os.Stderr)
+	plasma := newVM()
+	// exit is available even when the os module is not
 	plasma.Load("exit", func(plasma *vm.Plasma) *vm.Value {
-		return plasma.NewBuiltInFunction(plasma.Symbols(),
+		return plasma.NewBuiltInFunctionWithSignature(
+			plasma.Symbols(),
+			vm.Signature{
+				Name:       magic_functions.Exit,
+				Parameters: []vm.Parameter{{Name: "code", Types: []vm.TypeId{vm.IntId}, Optional: true}},
+			},
 			func(argument ...*vm.Value) (*vm.Value, error) {
-				if len(argument) == 0 {
-					os.Exit(0)
-				} else {
-					os.Exit(int(argument[0].Int()))
+				code := 0
+				if len(argument) > 0 {
+					code = int(argument[0].Int())
 				}
-				return plasma.None(), nil
-			})
+				return nil, &vm.ExitError{Code: code}
+			},
+		)
 	})
 	scanner := bufio.NewScanner(os.Stdin)
 	scanner.Split(splitFunc())
@@ -67,11 +75,20 @@ func repl() {
 			onError("REPL", "Keyboard interruption")
 		case executeError := <-errorChannel:
 			result := <-resultChannel
+			var exitError *vm.ExitError
+			if errors.As(executeError, &exitError) {
+				os.Exit(exitError.Code)
+			}
 			if executeError != nil {
 				onError("REPL", executeError)
 			} else if result != nil {
 				if result != plasma.None() {
-					_, _ = fmt.Fprintf(os.Stdout, "%s\n", result.String())
+					s, renderError := plasma.Repr(result)
+					if renderError != nil {
+						onError("REPL", renderError)
+						continue
+					}
+					_, _ = fmt.Fprintf(os.Stdout, "%s\n", s)
 				}
 			}
 		}
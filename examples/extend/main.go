@@ -1,33 +1,33 @@
 package main
 
 import (
+	"errors"
 	"github.com/shoriwe/gplasma/pkg/vm"
 	"os"
 )
 
 const myScript = `
-args = get_args()
+args = os.args()
 if args.__len__() > 1
-    println(args.__string__())
-else
-    println("No")
+    println(args.__string__(), host_name)
+    os.exit(0)
 end
+println("No")
+os.exit(1)
 `
 
 func main() {
 	plasma := vm.NewVM(os.Stdin, os.Stdout, os.Stderr)
-	plasma.Load("get_args", func(plasma *vm.Plasma) *vm.Value {
-		return plasma.NewBuiltInFunction(plasma.Symbols(),
-			func(argument ...*vm.Value) (*vm.Value, error) {
-				tupleValues := make([]*vm.Value, 0, len(os.Args))
-				for _, cmdArg := range os.Args {
-					tupleValues = append(tupleValues, plasma.NewString([]byte(cmdArg)))
-				}
-				return plasma.NewTuple(tupleValues), nil
-			})
+	plasma.SetOS(vm.HostOS(os.Args))
+	plasma.Load("host_name", func(plasma *vm.Plasma) *vm.Value {
+		return plasma.NewString([]byte("extend"))
 	})
 	_, errorChannel, _ := plasma.ExecuteString(myScript)
 	err := <-errorChannel
+	var exitError *vm.ExitError
+	if errors.As(err, &exitError) {
+		os.Exit(exitError.Code)
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -1,5 +1,7 @@
 package ast2
 
+import "math/big"
+
 const (
 	Not UnaryOperator = iota
 	Positive
@@ -91,6 +93,11 @@ type (
 		Value int64
 	}
 
+	BigInteger struct {
+		Expression
+		Value *big.Int
+	}
+
 	Float struct {
 		Expression
 		Value float64
@@ -1,5 +1,7 @@
 package ast3
 
+import "math/big"
+
 type (
 	Expression interface {
 		Node
@@ -54,6 +56,11 @@ type (
 		Value int64
 	}
 
+	BigInteger struct {
+		Expression
+		Value *big.Int
+	}
+
 	Float struct {
 		Expression
 		Value float64
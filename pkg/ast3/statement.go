@@ -39,4 +39,9 @@ type (
 		Statement
 		X Expression
 	}
+	// DeferBlock defers statements that run in the scope deferring them, like the END block of the program
+	DeferBlock struct {
+		Statement
+		Body []Node
+	}
 )
@@ -13,3 +13,14 @@ func (a *assembler) Defer(defer_ *ast3.Defer) []byte {
 	result = append(result, expression...)
 	return result
 }
+
+func (a *assembler) DeferBlock(defer_ *ast3.DeferBlock) []byte {
+	body := make([]byte, 0, len(defer_.Body))
+	for _, node := range defer_.Body {
+		body = append(body, a.assemble(node)...)
+	}
+	result := []byte{opcodes.Defer}
+	result = append(result, common.IntToBytes(len(body))...)
+	result = append(result, body...)
+	return result
+}
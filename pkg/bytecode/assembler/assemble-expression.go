@@ -27,6 +27,8 @@ func (a *assembler) Expression(expr ast3.Expression) []byte {
 		return a.Identifier(e)
 	case *ast3.Integer:
 		return a.Integer(e)
+	case *ast3.BigInteger:
+		return a.BigInteger(e)
 	case *ast3.Float:
 		return a.Float(e)
 	case *ast3.String:
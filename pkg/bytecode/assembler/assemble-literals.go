@@ -13,6 +13,15 @@ func (a *assembler) Integer(integer *ast3.Integer) []byte {
 	return result
 }
 
+func (a *assembler) BigInteger(integer *ast3.BigInteger) []byte {
+	contents := integer.Value.String()
+	var result []byte
+	result = append(result, opcodes.BigInteger)
+	result = append(result, common.IntToBytes(len(contents))...)
+	result = append(result, contents...)
+	return result
+}
+
 func (a *assembler) Float(float *ast3.Float) []byte {
 	var result []byte
 	result = append(result, opcodes.Float)
@@ -115,6 +115,10 @@ func (a *assembler) enumLabels(bytecode []byte) map[int64]int64 {
 			index++
 			bytesLength := common.BytesToInt(bytecode[index : index+8])
 			index += 8 + bytesLength
+		case opcodes.BigInteger:
+			index++
+			numberLength := common.BytesToInt(bytecode[index : index+8])
+			index += 8 + numberLength
 		case opcodes.True:
 			index++
 		case opcodes.False:
@@ -222,6 +226,10 @@ func (a *assembler) resolveLabels(bytecode []byte, labels map[int64]int64) []byt
 			index++
 			bytesLength := common.BytesToInt(bytecode[index : index+8])
 			index += 8 + bytesLength
+		case opcodes.BigInteger:
+			index++
+			numberLength := common.BytesToInt(bytecode[index : index+8])
+			index += 8 + numberLength
 		case opcodes.True:
 			index++
 		case opcodes.False:
@@ -28,6 +28,7 @@ const (
 	None
 	Selector
 	Super
+	BigInteger
 )
 
 var OpCodes = map[byte]string{
@@ -58,4 +59,5 @@ var OpCodes = map[byte]string{
 	None:             "None",
 	Selector:         "Selector",
 	Super:            "Super",
+	BigInteger:       "BigInteger",
 }
@@ -1,9 +1,13 @@
 package magic_functions
 
 const (
-	Append = "append"
-	Clear  = "clear"
-	Pop    = "pop"
-	Insert = "insert"
-	Remove = "remove"
+	Append       = "append"
+	Clear        = "clear"
+	Pop          = "pop"
+	Insert       = "insert"
+	Remove       = "remove"
+	Sort         = "sort"
+	Reverse      = "reverse"
+	Extend       = "extend"
+	BinarySearch = "binary_search"
 )
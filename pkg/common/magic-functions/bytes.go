@@ -0,0 +1,10 @@
+package magic_functions
+
+const (
+	Hex        = "hex"
+	FromHex    = "from_hex"
+	Base64     = "base64"
+	FromBase64 = "from_base64"
+	Base32     = "base32"
+	FromBase32 = "from_base32"
+)
@@ -0,0 +1,12 @@
+package magic_functions
+
+const (
+	Read     = "read"
+	ReadLine = "read_line"
+	Write    = "write"
+	Seek     = "seek"
+	Tell     = "tell"
+	Close    = "close"
+	ReadAll  = "read_all"
+	Flush    = "flush"
+)
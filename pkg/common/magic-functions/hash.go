@@ -0,0 +1,10 @@
+package magic_functions
+
+const (
+	Keys       = "keys"
+	Values     = "values"
+	Items      = "items"
+	GetDefault = "get"
+	SetDefault = "set_default"
+	Update     = "update"
+)
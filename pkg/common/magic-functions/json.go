@@ -0,0 +1,6 @@
+package magic_functions
+
+const (
+	Dumps = "dumps"
+	Loads = "loads"
+)
@@ -48,4 +48,6 @@ const (
 	SubClasses         = "__sub_classes__"
 	Copy               = "__copy__"
 	Iter               = "__iter__"
+	Hash               = "__hash__"
+	Repr               = "__repr__"
 )
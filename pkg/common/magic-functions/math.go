@@ -0,0 +1,31 @@
+package magic_functions
+
+const (
+	Pi    = "pi"
+	E     = "e"
+	Inf   = "inf"
+	NaN   = "nan"
+	Sqrt  = "sqrt"
+	Pow   = "pow"
+	Exp   = "exp"
+	Log   = "log"
+	Log2  = "log2"
+	Log10 = "log10"
+	Sin   = "sin"
+	Cos   = "cos"
+	Tan   = "tan"
+	Asin  = "asin"
+	Acos  = "acos"
+	Atan  = "atan"
+	Atan2 = "atan2"
+	Floor = "floor"
+	Ceil  = "ceil"
+	Round = "round"
+	Abs   = "abs"
+	Hypot = "hypot"
+	Gcd   = "gcd"
+	Lcm   = "lcm"
+	IsNaN = "is_nan"
+	IsInf = "is_inf"
+	Clamp = "clamp"
+)
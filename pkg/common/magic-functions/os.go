@@ -0,0 +1,14 @@
+package magic_functions
+
+const (
+	Args    = "args"
+	Env     = "env"
+	SetEnv  = "set"
+	ListEnv = "list"
+	Cwd     = "cwd"
+	Exit    = "exit"
+	Run     = "run"
+	Status  = "status"
+	Stdout  = "stdout"
+	Stderr  = "stderr"
+)
@@ -0,0 +1,11 @@
+package magic_functions
+
+const (
+	RandomInt   = "int"
+	RandomFloat = "float"
+	Choice      = "choice"
+	Shuffle     = "shuffle"
+	Sample      = "sample"
+	RandomBytes = "bytes"
+	Random      = "Random"
+)
@@ -0,0 +1,14 @@
+package magic_functions
+
+const (
+	Compile = "compile"
+	Pattern = "pattern"
+	Match   = "match"
+	Search  = "search"
+	FindAll = "find_all"
+	Group   = "group"
+	Groups  = "groups"
+	Named   = "named"
+	Start   = "start"
+	Span    = "span"
+)
@@ -0,0 +1,9 @@
+package magic_functions
+
+const (
+	AddElement = "add"
+	Discard    = "discard"
+	IsSubset   = "is_subset"
+	IsSuperset = "is_superset"
+	IsDisjoint = "is_disjoint"
+)
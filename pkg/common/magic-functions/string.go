@@ -8,3 +8,27 @@ const (
 	Count = "count"
 	Index = "index"
 )
+
+const (
+	Strip      = "strip"
+	LeftStrip  = "lstrip"
+	RightStrip = "rstrip"
+	Replace    = "replace"
+	StartsWith = "starts_with"
+	EndsWith   = "ends_with"
+	Find       = "find"
+	RightFind  = "rfind"
+	SplitLines = "split_lines"
+	Partition  = "partition"
+	LeftJust   = "ljust"
+	RightJust  = "rjust"
+	Center     = "center"
+	Repeat     = "repeat"
+	Title      = "title"
+	IsDigit    = "is_digit"
+	IsAlpha    = "is_alpha"
+	IsSpace    = "is_space"
+	Format     = "format"
+	Encode     = "encode"
+	Decode     = "decode"
+)
@@ -0,0 +1,26 @@
+package magic_functions
+
+const (
+	Now        = "now"
+	Monotonic  = "monotonic"
+	Sleep      = "sleep"
+	Parse      = "parse"
+	DateTime   = "DateTime"
+	Duration   = "Duration"
+	FromUnix   = "from_unix"
+	Year       = "year"
+	Month      = "month"
+	Day        = "day"
+	Hour       = "hour"
+	Minute     = "minute"
+	Second     = "second"
+	Nanosecond = "nanosecond"
+	Weekday    = "weekday"
+	YearDay    = "year_day"
+	Unix       = "unix"
+	Zone       = "zone"
+	InZone     = "in_zone"
+	UTC        = "utc"
+	Seconds    = "seconds"
+	Millis     = "milliseconds"
+)
@@ -1,21 +1,54 @@
 package special_symbols
 
 const (
-	Self     = "self"
-	Value    = "Value"
-	String   = "String"
-	Bytes    = "Bytes"
-	Bool     = "Bool"
-	None     = "None"
-	Int      = "Int"
-	Float    = "Float"
-	Array    = "Array"
-	Tuple    = "Tuple"
-	Hash     = "Hash"
-	Function = "Function"
-	Class    = "Class"
-	Input    = "input"
-	Print    = "print"
-	Println  = "println"
-	Range    = "range"
+	Self       = "self"
+	Value      = "Value"
+	String     = "String"
+	Bytes      = "Bytes"
+	Bool       = "Bool"
+	None       = "None"
+	Int        = "Int"
+	Float      = "Float"
+	Array      = "Array"
+	Tuple      = "Tuple"
+	Hash       = "Hash"
+	Set        = "Set"
+	Function   = "Function"
+	Class      = "Class"
+	Input      = "input"
+	Stdin      = "stdin"
+	Stdout     = "stdout"
+	Stderr     = "stderr"
+	Print      = "print"
+	Println    = "println"
+	Range      = "range"
+	Sorted     = "sorted"
+	Min        = "min"
+	Max        = "max"
+	Sum        = "sum"
+	Map        = "map"
+	Filter     = "filter"
+	Zip        = "zip"
+	Enumerate  = "enumerate"
+	Take       = "take"
+	Skip       = "skip"
+	Chain      = "chain"
+	Reduce     = "reduce"
+	Any        = "any"
+	All        = "all"
+	List       = "list"
+	Math       = "math"
+	Pack       = "pack"
+	Unpack     = "unpack"
+	JSON       = "json"
+	Regex      = "regex"
+	Time       = "time"
+	Open       = "open"
+	ReadFile   = "read_file"
+	WriteFile  = "write_file"
+	ListDir    = "list_dir"
+	Exists     = "exists"
+	OS         = "os"
+	Subprocess = "subprocess"
+	Random     = "random"
 )
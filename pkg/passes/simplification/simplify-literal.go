@@ -1,10 +1,12 @@
 package simplification
 
 import (
+	"errors"
 	"fmt"
 	"github.com/shoriwe/gplasma/pkg/ast"
 	"github.com/shoriwe/gplasma/pkg/ast2"
 	"github.com/shoriwe/gplasma/pkg/lexer"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -20,10 +22,18 @@ var directCharEscapeValue = map[rune][]rune{
 	'?': {'\\', '?'},
 }
 
-func (simplify *simplifyPass) simplifyInteger(s string) *ast2.Integer {
+func (simplify *simplifyPass) simplifyInteger(s string) ast2.Expression {
 	s = strings.ReplaceAll(strings.ToLower(s), "_", "")
 	value, parseError := strconv.ParseInt(s, 0, 64)
-	if parseError != nil {
+	if errors.Is(parseError, strconv.ErrRange) {
+		bigValue, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			panic(parseError)
+		}
+		return &ast2.BigInteger{
+			Value: bigValue,
+		}
+	} else if parseError != nil {
 		panic(parseError)
 	}
 	return &ast2.Integer{
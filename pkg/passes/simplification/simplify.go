@@ -48,15 +48,21 @@ func Simplify(program *ast.Program) (ast2.Program, error) {
 			body = append(body, simp.Node(node))
 		}
 		if program.End != nil {
-			end = make([]ast2.Node, 0, len(program.End.Body))
+			endBody := make([]ast2.Node, 0, len(program.End.Body))
 			for _, node := range program.End.Body {
-				end = append(end, simp.Node(node))
+				endBody = append(endBody, simp.Node(node))
+			}
+			// END runs as deferred code of the program, so it also runs when the script exits early
+			end = []ast2.Node{
+				&ast2.DeferBlock{
+					Body: endBody,
+				},
 			}
 		}
 		result := make(ast2.Program, 0, len(begin)+len(body)+len(end))
 		result = append(result, begin...)
-		result = append(result, body...)
 		result = append(result, end...)
+		result = append(result, body...)
 		resultChan <- result
 		errorChan <- nil
 	}(resultChan, errorChan)
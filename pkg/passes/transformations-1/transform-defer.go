@@ -12,3 +12,15 @@ func (transform *transformPass) Defer(def *ast2.Defer) []ast3.Node {
 		},
 	}
 }
+
+func (transform *transformPass) DeferBlock(def *ast2.DeferBlock) []ast3.Node {
+	body := make([]ast3.Node, 0, len(def.Body))
+	for _, node := range def.Body {
+		body = append(body, transform.Node(node)...)
+	}
+	return []ast3.Node{
+		&ast3.DeferBlock{
+			Body: body,
+		},
+	}
+}
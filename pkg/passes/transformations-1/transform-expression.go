@@ -25,6 +25,8 @@ func (transform *transformPass) Expression(expr ast2.Expression) ast3.Expression
 		return transform.Identifier(e)
 	case *ast2.Integer:
 		return transform.Integer(e)
+	case *ast2.BigInteger:
+		return transform.BigInteger(e)
 	case *ast2.Float:
 		return transform.Float(e)
 	case *ast2.String:
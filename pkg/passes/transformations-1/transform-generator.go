@@ -204,6 +204,8 @@ func (gt *generatorTransform) resolve(node ast3.Node, symbols map[string]struct{
 		return []ast3.Node{n}
 	case *ast3.Integer:
 		return []ast3.Node{n}
+	case *ast3.BigInteger:
+		return []ast3.Node{n}
 	case *ast3.Float:
 		return []ast3.Node{n}
 	case *ast3.String:
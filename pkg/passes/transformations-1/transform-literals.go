@@ -11,6 +11,12 @@ func (transform *transformPass) Integer(integer *ast2.Integer) *ast3.Integer {
 	}
 }
 
+func (transform *transformPass) BigInteger(integer *ast2.BigInteger) *ast3.BigInteger {
+	return &ast3.BigInteger{
+		Value: integer.Value,
+	}
+}
+
 func (transform *transformPass) Float(float *ast2.Float) *ast3.Float {
 	return &ast3.Float{
 		Value: float.Value,
@@ -0,0 +1,3 @@
+a = []
+a.append(a)
+println(json.dumps(a))
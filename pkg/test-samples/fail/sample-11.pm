@@ -0,0 +1 @@
+r = regex.compile("(unclosed")
@@ -0,0 +1 @@
+leap = time.DateTime(2023, 2, 29)
@@ -0,0 +1 @@
+contents = read_file("config.txt")
@@ -0,0 +1 @@
+roll = random.int(6, 1)
@@ -0,0 +1,2 @@
+values = Set([1, 2])
+values.remove(3)
@@ -0,0 +1 @@
+range()
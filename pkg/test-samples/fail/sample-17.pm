@@ -0,0 +1 @@
+println(unpack(">s", pack(">IBB", 2, 255, 254)))
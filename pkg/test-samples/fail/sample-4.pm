@@ -0,0 +1 @@
+println(sorted([3, "a", 1]))
@@ -0,0 +1 @@
+println(reduce(lambda a, b: a + b, []))
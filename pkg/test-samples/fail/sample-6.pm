@@ -0,0 +1 @@
+println("{} {missing}".format(1))
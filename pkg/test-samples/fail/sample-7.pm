@@ -0,0 +1 @@
+println("ñ".encode()[(0, 1)].decode())
@@ -0,0 +1 @@
+println(pack(">b", 200))
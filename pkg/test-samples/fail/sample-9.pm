@@ -0,0 +1 @@
+println(unpack(">I", b"abc"))
@@ -11,10 +11,52 @@ var (
 	sample2 string
 	//go:embed sample-3.pm
 	sample3 string
+	//go:embed sample-4.pm
+	sample4 string
+	//go:embed sample-5.pm
+	sample5 string
+	//go:embed sample-6.pm
+	sample6 string
+	//go:embed sample-7.pm
+	sample7 string
+	//go:embed sample-8.pm
+	sample8 string
+	//go:embed sample-9.pm
+	sample9 string
+	//go:embed sample-10.pm
+	sample10 string
+	//go:embed sample-11.pm
+	sample11 string
+	//go:embed sample-12.pm
+	sample12 string
+	//go:embed sample-13.pm
+	sample13 string
+	//go:embed sample-14.pm
+	sample14 string
+	//go:embed sample-15.pm
+	sample15 string
+	//go:embed sample-16.pm
+	sample16 string
+	//go:embed sample-17.pm
+	sample17 string
 )
 
 var Samples = map[string]string{
-	"sample-1.pm": sample1,
-	"sample-2.pm": sample2,
-	"sample-3.pm": sample3,
+	"sample-1.pm":  sample1,
+	"sample-2.pm":  sample2,
+	"sample-3.pm":  sample3,
+	"sample-4.pm":  sample4,
+	"sample-5.pm":  sample5,
+	"sample-6.pm":  sample6,
+	"sample-7.pm":  sample7,
+	"sample-8.pm":  sample8,
+	"sample-9.pm":  sample9,
+	"sample-10.pm": sample10,
+	"sample-11.pm": sample11,
+	"sample-12.pm": sample12,
+	"sample-13.pm": sample13,
+	"sample-14.pm": sample14,
+	"sample-15.pm": sample15,
+	"sample-16.pm": sample16,
+	"sample-17.pm": sample17,
 }
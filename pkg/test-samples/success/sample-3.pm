@@ -1,7 +1,7 @@
 println((1 + 2 / 3))
 println(25**(1/2))
 println("Hello-" * 5)
-println((1, 2, 4 + 5 / 6 ** 2, 10, "hello * 5 " * 0).__string__() == "(1, 2, 4.138889, 10, )")
+println((1, 2, 4 + 5 / 6 ** 2, 10, "hello * 5 " * 0).__string__() == "(1, 2, 4.138889, 10, \"\")")
 println(1.__bool__() and (1, 2, 3, 4).__bool__())
 println(1.__bool__() or (1, 2, 3, 4).__bool__())
 println(1.__bool__() xor (1, 2, 3, 4).__bool__())
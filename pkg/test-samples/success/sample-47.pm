@@ -0,0 +1,37 @@
+class Counter
+    def __init__()
+        self.value = 0
+    end
+
+    def increment()
+        self.value += 1
+    end
+end
+
+c = Counter()
+for i in range(0, 3)
+    c.increment()
+    println(c.value)
+end
+
+def replacement()
+    return "replaced"
+end
+
+results = []
+for i in range(0, 2)
+    if i == 1
+        c.increment = replacement
+    end
+    results.append(c.increment())
+end
+println(results.__string__())
+
+value = "global"
+c.value = "local"
+for i in range(0, 2)
+    if i == 1
+        delete c.value
+    end
+    println(c.value)
+end
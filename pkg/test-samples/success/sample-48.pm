@@ -0,0 +1,29 @@
+class Point
+    def __init__(x, y)
+        self.x = x
+        self.y = y
+    end
+
+    def __hash__()
+        return self.x * 31 + self.y
+    end
+
+    def __equal__(other)
+        return self.x == other.x and self.y == other.y
+    end
+end
+
+h = {(1, 2): "tuple", none: "none", 1: "int", "1": "string"}
+println(h[(1, 2)])
+println(h[none])
+println(h[1.0])
+println(h["1"])
+h[Point(1, 2)] = "point"
+println(h[Point(1, 2)])
+println(Point(2, 1) in h)
+h[Point(1, 2)] = "updated"
+println(h[Point(1, 2)])
+println(h.__len__())
+delete h[1]
+println(1 in h)
+println(h.__len__())
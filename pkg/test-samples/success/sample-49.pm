@@ -0,0 +1,25 @@
+h = {"a": 1, "b": 2, "c": 3}
+for key in h
+    println(key)
+end
+
+println(h.keys().__string__())
+println(h.values().__string__())
+for key, value in h.items()
+    println(key, value)
+end
+
+println(h.get("a", 0))
+println(h.get("z", 0))
+println(h.get("z"))
+
+println(h.set_default("a", 10))
+println(h.set_default("d", 4))
+
+h.update({"b": 20, "e": 5})
+println(h.__string__())
+
+println(h.pop("a"))
+println(h.pop("a", "missing"))
+println(h.__string__())
+println({}.__string__())
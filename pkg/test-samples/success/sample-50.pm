@@ -0,0 +1,26 @@
+a = 9223372036854775807
+b = a + 1
+println(b)
+println(b - 1)
+println((b - 1).__class__() == Int)
+println(2 ** 100)
+println(99999999999999999999999999999)
+println(-9223372036854775808)
+println(0xFFFFFFFFFFFFFFFFFF)
+println(b * b)
+println((b * b) // b)
+println((b * b) % 7)
+println(b > a, a < b, b == a + 1, b != a)
+println(b == 9223372036854775808.0)
+println(b.big_endian().__array__().__string__())
+println(0.from_big(b.big_endian()))
+println(0.from_little(b.little_endian()))
+println((-b).big_endian().__array__().__string__())
+println(0.from_big((-b - 1).big_endian()))
+println({b: "big"}[9223372036854775808.0])
+println(1 << 70)
+println((1 << 70) >> 69)
+println(~b)
+println(b | 1, b & 1, b ^ b)
+println(b / 2)
+println(2 ** -1)
@@ -0,0 +1,42 @@
+a = [1, "two", 3.5, none, true]
+println(a)
+a.append(a)
+println(a)
+h = {"name": "plasma", "tags": ("a", "b")}
+h["self"] = h
+println(h)
+println(("single",))
+println(["x\ty", b"bytes"])
+
+class Point
+    def __init__(x, y)
+        self.x = x
+        self.y = y
+    end
+    def __string__()
+        return "Point(" + self.x.__string__() + ", " + self.y.__string__() + ")"
+    end
+end
+
+class Node
+    def __init__(name)
+        self.name = name
+        self.children = []
+    end
+    def __string__()
+        return self.name
+    end
+    def __repr__()
+        return "<" + self.name + " " + self.children.__string__() + ">"
+    end
+end
+
+p = Point(1, 2)
+println(p)
+println([p, Point(3, 4)])
+root = Node("root")
+root.children.append(Node("leaf"))
+root.children.append(root)
+println(root)
+println([root])
+println("text".__repr__(), 10.__repr__())
@@ -0,0 +1,46 @@
+a = [5, 3, 8, 1, 9, 2]
+a.sort()
+println(a)
+a.sort(none, true)
+println(a)
+words = ["pear", "fig", "banana", "kiwi", "apple"]
+words.sort(lambda w: w.__len__())
+println(words)
+println(sorted(words))
+println(sorted((3, 1, 2), none, true))
+println(sorted({"b": 1, "a": 2}))
+
+class Version
+    def __init__(major, minor)
+        self.major = major
+        self.minor = minor
+    end
+    def __less_than__(other)
+        if self.major == other.major
+            return self.minor < other.minor
+        end
+        return self.major < other.major
+    end
+    def __string__()
+        return self.major.__string__() + "." + self.minor.__string__()
+    end
+end
+
+versions = [Version(1, 2), Version(0, 9), Version(1, 0)]
+for v in sorted(versions)
+    println(v)
+end
+println(max(versions))
+println(min([4, 2.5, 7]), max([4, 2.5, 7]))
+println(min(words, lambda w: w.__len__()))
+println(sum([1, 2, 3]), sum((1.5, 2)), sum(range(0, 5)))
+println(sum(["a", "b", "c"], ""))
+b = [1, 2, 3]
+b.reverse()
+println(b)
+b.extend((4, 5))
+b.extend(range(6, 8))
+println(b)
+println([1, 2, 1, 1.0, "1"].count(1))
+c = [1, 3, 5, 7, 9, 11]
+println(c.binary_search(7), c.binary_search(4), c.binary_search(1), c.binary_search(12))
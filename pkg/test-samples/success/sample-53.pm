@@ -0,0 +1,28 @@
+def double(x)
+    return x * 2
+end
+
+gen numbers(limit)
+    for i in range(0, limit)
+        yield i
+    end
+end
+
+println(list(map(double, [1, 2, 3])))
+println(list(map(lambda a, b: a + b, (1, 2, 3), [10, 20])))
+println(list(filter(lambda x: x % 2 == 0, range(0, 10))))
+println(list(filter(none, [0, 1, "", "a", none, true])))
+println(list(zip(("a", "b", "c"), range(1, 10), [true, false])))
+for pair in enumerate(["a", "b"], 1)
+    println(pair)
+end
+println(list(take(numbers(1000000), 3)))
+println(list(skip(range(0, 6), 4)))
+println(list(chain([1, 2], (3,), range(4, 6))))
+println(list(take(map(double, filter(lambda x: x > 2, numbers(100))), 4)))
+println(reduce(lambda a, b: a * b, range(1, 6)))
+println(reduce(lambda a, b: a + b, [], 100))
+println(any([0, none, 3]), any([]), all([1, "a"]), all([1, 0]), all([]))
+println(list({"x": 1, "y": 2}))
+lazy = map(double, [5, 6])
+println(lazy.__has_next__(), lazy.__has_next__(), lazy.__next__(), lazy.__next__(), lazy.__has_next__())
@@ -0,0 +1,13 @@
+println(math.pi, math.e)
+println(math.inf, math.is_inf(math.inf), math.is_nan(math.nan), math.is_nan(1))
+println(math.sqrt(16), math.sqrt(2.25), math.pow(2, 10), math.exp(0))
+println(math.log(math.e), math.log(8, 2), math.log2(1024), math.log10(1000))
+println(math.sin(0), math.cos(0), math.tan(0))
+println(math.asin(1) * 2 == math.pi, math.acos(1), math.atan(1) * 4 == math.pi, math.atan2(1, 1))
+println(math.floor(2.7), math.floor(-2.5), math.ceil(2.1), math.ceil(5))
+println(math.round(2.5), math.round(-2.4), math.round(3.14159, 2), math.round(7))
+println(math.abs(-5), math.abs(3.5), math.abs(-9223372036854775808))
+println(math.hypot(3, 4), math.gcd(12, 18), math.lcm(4, 6), math.gcd(0, 5))
+println(math.clamp(15, 0, 10), math.clamp(-1, 0, 10), math.clamp(2.5, 0, 10))
+println(math.floor(100000000000000000000.5))
+println(math.sqrt(true))
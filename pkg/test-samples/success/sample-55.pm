@@ -0,0 +1,16 @@
+println("[" + "  padded \t".strip() + "]", "[" + "  left".lstrip() + "]", "[" + "right  ".rstrip() + "]")
+println("xxhixx".strip("x"), "--a--".lstrip("-"), "--a--".rstrip("-"))
+println("a-b-c-d".replace("-", "+"), "a-b-c-d".replace("-", "+", 2))
+println("plasma".starts_with("pla"), "plasma".ends_with("ma"), "plasma".starts_with("ma"))
+println("banana".find("an"), "banana".find("an", 2), "banana".find("x"), "banana".rfind("an"))
+println("one\ntwo\r\nthree\n".split_lines())
+println("key=value=x".partition("="), "novalue".partition("="))
+println("[" + "ab".ljust(5) + "]", "[" + "ab".rjust(5, "*") + "]", "[" + "ab".center(6, "-") + "]")
+println("ab".repeat(3), "hello world of plasma".title())
+println("12345".is_digit(), "12a".is_digit(), "abc".is_alpha(), " \t".is_space(), "".is_space())
+println("{} + {} = {}".format(1, 2, 3))
+println("{1} {0} {1}".format("a", "b"))
+println("{name} is {age} years old".format({"name": "Plasma", "age": 3}))
+println("{{literal}} {}".format([1, "two"]))
+println(b"  bytes  ".strip(), b"a,b".partition(b","), b"x".center(5, b".").__len__())
+println(b"{} bytes".format(10))
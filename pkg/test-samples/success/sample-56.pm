@@ -0,0 +1,14 @@
+name = "José Ñandú 🚀"
+println(name.__len__())
+println(name[3], name[11], name[(5, 10)])
+for c in "añ🚀"
+    println(c)
+end
+println(name.upper(), name.lower())
+println("Ñ" in name, 209 in name, "ñ" in name)
+println("añ🚀".__array__())
+println("día día".find("í"), "día día".rfind("día"), "día día".index("a"))
+println("[" + "ñ".center(5, "*") + "]")
+encoded = "ñ🚀".encode()
+println(encoded.__len__(), encoded.decode() == "ñ🚀", encoded.decode().__len__())
+println("a,b".split(","))
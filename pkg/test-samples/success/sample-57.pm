@@ -0,0 +1,15 @@
+data = "Plasma".encode()
+println(data.hex(), Bytes.from_hex("506c61736d61") == data)
+println(data.base64(), Bytes.from_base64("UGxhc21h").decode())
+println(data.base32(), Bytes.from_base32(data.base32()) == data)
+header = pack(">BHi", 1, 513, -2)
+println(header.hex())
+println(unpack(">BHi", header))
+le = pack("<hIq", -1, 4294967295, -9223372036854775808)
+println(le.hex())
+println(unpack("<hIq", le))
+println(unpack("<Q", pack("<Q", 18446744073709551615)))
+floats = pack("!fd", 1.5, -0.25)
+println(floats.hex(), unpack("!fd", floats))
+record = pack("<2s B", "héllo", b"raw", 7)
+println(record.__len__(), unpack("<2sB", record))
@@ -0,0 +1,8 @@
+data = json.loads("{\"name\": \"plasma\", \"version\": 1, \"ratio\": 0.5, \"tags\": [\"vm\", null, true], \"nested\": {\"big\": 123456789012345678901234567890}}")
+println(data)
+println(data["tags"][1] == none, data["nested"]["big"] + 1)
+println(json.dumps(data))
+println(json.dumps({"a": [1, 2.0, (3, "x")], 1: false, "empty": [], "obj": {}}, 2))
+println(json.dumps("quote \" <tag> ñ"))
+println(json.loads(json.dumps(data)) == data)
+println(json.loads("  [1, 2]  "))
@@ -0,0 +1,16 @@
+date = regex.compile("(?P<year>\\d{4})-(?P<month>\\d{2})-(?P<day>\\d{2})")
+println(date.pattern)
+m = date.match("2024-03-15 release")
+println(m.group(), m.group(1), m.group("month"), m.groups())
+println(m.named())
+println(date.match("on 2024-03-15") == none)
+s = date.search("released on 2024-03-15!")
+println(s.group("day"), s.start(), s.span())
+words = regex.compile("\\w+")
+found = words.find_all("héllo wörld, plasma")
+println(found.__len__(), found[0].group(), found[1].start(), found[2].group())
+println(words.replace("a b c", "<$0>"), words.replace("a b c", "_", 2))
+println(words.replace("one two", lambda match: match.group().upper()))
+println(regex.compile("\\s*,\\s*").split("a , b,c ,d"))
+println(regex.compile("x").split("axbxc", 1))
+println(regex.compile("(a)|(b)").match("b").groups())
@@ -0,0 +1,27 @@
+DateTime = time.DateTime
+Duration = time.Duration
+start = DateTime(2024, 2, 28, 22, 30, 0, 0, "UTC")
+println(start)
+later = start + Duration(5400)
+println(later, later.day(), later.month(), later.weekday(), later.year_day())
+println(later - start, (later - start).seconds(), (later - start).milliseconds())
+println(later > start, later == start + Duration(5400), later - Duration(5400) == start)
+ny = start.in_zone("America/New_York")
+println(ny, ny.zone(), ny.hour(), ny == start)
+println(ny.format("%A %d %B %Y %I:%M %p %z %Z"))
+println(start.format("%Y-%m-%dT%H:%M:%S.%f %j %y %a %b %%"))
+parsed = time.parse("2024-03-10 01:59:59 -0500", "%Y-%m-%d %H:%M:%S %z")
+println(parsed, parsed.utc())
+println(time.parse("10/Mar/2024 09:15PM", "%d/%b/%Y %I:%M%p", "Europe/Madrid"))
+println(time.parse("2024 061", "%Y %j"))
+println(time.parse("1.5", "%S.%f").nanosecond())
+d = Duration(1.5) * 3 + Duration(0.25)
+println(d, d / 2, d / Duration(0.25), -d, d.milliseconds())
+println(sorted([Duration(3), Duration(1), Duration(2)]))
+println(DateTime.from_unix(86400).unix(), DateTime.from_unix(86400))
+seen = {start: "start"}
+println(seen[DateTime(2024, 2, 28, 17, 30, 0, 0, "America/New_York")])
+println(start.__class__() == DateTime)
+before = time.monotonic()
+time.sleep(0)
+println(time.monotonic() >= before)
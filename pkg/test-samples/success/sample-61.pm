@@ -0,0 +1,21 @@
+a = random.Random(42)
+b = random.Random(42)
+first = []
+second = []
+for i in range(0, 5)
+    first.append(a.int(1, 6))
+    second.append(b.int(1, 6))
+end
+println(first == second, all(map(lambda x: 1 <= x and x <= 6, first)))
+println(a.float() == b.float())
+println(a.bytes(8) == b.bytes(8), a.bytes(3).__len__())
+values = [1, 2, 3, 4, 5, 6, 7, 8]
+shuffled = list(values)
+a.shuffle(shuffled)
+println(sorted(shuffled) == values)
+picked = a.sample(values, 3)
+println(picked.__len__(), all(map(lambda x: x in values, picked)))
+println(a.choice((10, 20, 30)) in (10, 20, 30))
+println(a.int(-5, -5), a.int(9223372036854775807, 9223372036854775808) >= 9223372036854775807)
+println(random.bytes(16).__len__(), random.float() < 1.0, random.int(0, 1) in (0, 1))
+println(random.Random(7).__class__() == random.Random)
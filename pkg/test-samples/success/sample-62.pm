@@ -0,0 +1,20 @@
+a = Set([1, 2, 2, 3])
+b = Set((3, 4))
+println(a)
+println(Set())
+println(a | b)
+println(a & b)
+println(a - b)
+println(a ^ b)
+println(Set([1]) <= a, Set([1]) < a, a < a, a >= Set([2]), a > a)
+println(a.is_subset(a | b), a.is_superset(Set([1, 3])), a.is_disjoint(Set([9])))
+a.add(7)
+a.remove(1)
+a.discard(100)
+println(a, 7 in a, 1 in a, a.__len__())
+println(Set([1, 2]) == Set([2, 1]), Set([1]) != Set([1]))
+for x in Set(["x", "y"])
+    println(x)
+end
+println(Bool(Set()), Bool(a), sorted(Set([3, 1, 2])))
+println(Set([(1, 2)]) | Set([(1, 2)]), Set([1, 2]).__copy__() == Set([1, 2]))
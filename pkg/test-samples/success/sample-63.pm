@@ -0,0 +1,10 @@
+class Plain
+    def __init__()
+        pass
+    end
+end
+
+o = Plain()
+println([o])
+println(o.__repr__())
+println([o], (o,))
@@ -0,0 +1,13 @@
+def show()
+    println("y:", y)
+end
+x = 1
+END
+    y = x + 1
+    show()
+    for i in range(0, 2)
+        total = i
+    end
+    println(total)
+end
+println("x:", x)
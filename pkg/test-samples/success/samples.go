@@ -189,6 +189,78 @@ var (
 	sample46 string
 	//go:embed result-46.txt
 	result46 string
+	//go:embed sample-47.pm
+	sample47 string
+	//go:embed result-47.txt
+	result47 string
+	//go:embed sample-48.pm
+	sample48 string
+	//go:embed result-48.txt
+	result48 string
+	//go:embed sample-49.pm
+	sample49 string
+	//go:embed result-49.txt
+	result49 string
+	//go:embed sample-50.pm
+	sample50 string
+	//go:embed result-50.txt
+	result50 string
+	//go:embed sample-51.pm
+	sample51 string
+	//go:embed result-51.txt
+	result51 string
+	//go:embed sample-52.pm
+	sample52 string
+	//go:embed result-52.txt
+	result52 string
+	//go:embed sample-53.pm
+	sample53 string
+	//go:embed result-53.txt
+	result53 string
+	//go:embed sample-54.pm
+	sample54 string
+	//go:embed result-54.txt
+	result54 string
+	//go:embed sample-55.pm
+	sample55 string
+	//go:embed result-55.txt
+	result55 string
+	//go:embed sample-56.pm
+	sample56 string
+	//go:embed result-56.txt
+	result56 string
+	//go:embed sample-57.pm
+	sample57 string
+	//go:embed result-57.txt
+	result57 string
+	//go:embed sample-58.pm
+	sample58 string
+	//go:embed result-58.txt
+	result58 string
+	//go:embed sample-59.pm
+	sample59 string
+	//go:embed result-59.txt
+	result59 string
+	//go:embed sample-60.pm
+	sample60 string
+	//go:embed result-60.txt
+	result60 string
+	//go:embed sample-61.pm
+	sample61 string
+	//go:embed result-61.txt
+	result61 string
+	//go:embed sample-62.pm
+	sample62 string
+	//go:embed result-62.txt
+	result62 string
+	//go:embed sample-63.pm
+	sample63 string
+	//go:embed result-63.txt
+	result63 string
+	//go:embed sample-64.pm
+	sample64 string
+	//go:embed result-64.txt
+	result64 string
 )
 
 type Script struct {
@@ -381,4 +453,76 @@ var Samples = map[string]Script{
 		Code:   sample46,
 		Result: result46,
 	},
+	"sample-47.pm": {
+		Code:   sample47,
+		Result: result47,
+	},
+	"sample-48.pm": {
+		Code:   sample48,
+		Result: result48,
+	},
+	"sample-49.pm": {
+		Code:   sample49,
+		Result: result49,
+	},
+	"sample-50.pm": {
+		Code:   sample50,
+		Result: result50,
+	},
+	"sample-51.pm": {
+		Code:   sample51,
+		Result: result51,
+	},
+	"sample-52.pm": {
+		Code:   sample52,
+		Result: result52,
+	},
+	"sample-53.pm": {
+		Code:   sample53,
+		Result: result53,
+	},
+	"sample-54.pm": {
+		Code:   sample54,
+		Result: result54,
+	},
+	"sample-55.pm": {
+		Code:   sample55,
+		Result: result55,
+	},
+	"sample-56.pm": {
+		Code:   sample56,
+		Result: result56,
+	},
+	"sample-57.pm": {
+		Code:   sample57,
+		Result: result57,
+	},
+	"sample-58.pm": {
+		Code:   sample58,
+		Result: result58,
+	},
+	"sample-59.pm": {
+		Code:   sample59,
+		Result: result59,
+	},
+	"sample-60.pm": {
+		Code:   sample60,
+		Result: result60,
+	},
+	"sample-61.pm": {
+		Code:   sample61,
+		Result: result61,
+	},
+	"sample-62.pm": {
+		Code:   sample62,
+		Result: result62,
+	},
+	"sample-63.pm": {
+		Code:   sample63,
+		Result: result63,
+	},
+	"sample-64.pm": {
+		Code:   sample64,
+		Result: result64,
+	},
 }
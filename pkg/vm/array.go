@@ -2,12 +2,13 @@ package vm
 
 import (
 	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
 )
 
 func (plasma *Plasma) arrayClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
 	class.SetAny(
-		Callback(func(argument ...*Value) (*Value, error) {
+		constructor(special_symbols.Array, []Parameter{param("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
 			return plasma.NewArray(argument[0].Values()), nil
 		}),
 	)
@@ -19,6 +20,10 @@ NewArray magic function:
 In                  __in__
 Equal              __equal__
 NotEqual            __not_equal__
+GreaterThan         __greater_than__
+GreaterOrEqualThan  __greater_or_equal_than__
+LessThan            __less_than__
+LessOrEqualThan     __less_or_equal_than__
 Mul                 __mul__
 Length              __len__
 Bool                __bool__
@@ -35,214 +40,228 @@ Index				index
 Pop					pop
 Insert				insert
 Remove				remove
+Sort				sort
+Reverse				reverse
+Extend				extend
+Count				count
+BinarySearch		binary_search
 */
 func (plasma *Plasma) NewArray(values []*Value) *Value {
 	result := plasma.NewValue(plasma.rootSymbols, ArrayId, plasma.array)
 	result.SetAny(values)
-	result.Set(magic_functions.In, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			for _, value := range result.GetValues() {
-				if value.Equal(argument[0]) {
-					return plasma.true, nil
-				}
-			}
-			return plasma.false, nil
-		}))
-	result.Set(magic_functions.Equal, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case ArrayId:
-				otherValues := argument[0].GetValues()
-				for index, value := range result.GetValues() {
-					if !value.Equal(otherValues[index]) {
-						return plasma.false, nil
-					}
-				}
-			}
-			return plasma.true, nil
-		}))
-	result.Set(magic_functions.NotEqual, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case ArrayId:
-				otherValues := argument[0].GetValues()
-				for index, value := range result.GetValues() {
-					if value.Equal(otherValues[index]) {
-						return plasma.false, nil
-					}
-				}
+	plasma.define(result.vtable, magic_functions.In, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		for _, value := range result.GetValues() {
+			if value.Equal(argument[0]) {
+				return plasma.true, nil
 			}
-			return plasma.true, nil
-		}))
-	result.Set(magic_functions.Mul, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId:
-				times := argument[0].GetInt64()
-				currentValues := result.GetValues()
-				newValues := make([]*Value, 0, int64(len(currentValues))*times)
-				for i := int64(0); i < times; i++ {
-					for _, value := range currentValues {
-						newValues = append(newValues, value)
-					}
-				}
-				return plasma.NewArray(newValues), nil
-			default:
-				return nil, NotOperable
-			}
-		}))
-	result.Set(magic_functions.Length, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewInt(int64(len(result.GetValues()))), nil
-		}))
-	result.Set(magic_functions.Bool, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(len(result.GetValues()) > 0), nil
-		}))
-	result.Set(magic_functions.String, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			var rawString []byte
-			rawString = append(rawString, '[')
-			for index, value := range result.GetValues() {
-				if index != 0 {
-					rawString = append(rawString, ',', ' ')
+		}
+		return plasma.false, nil
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		equal, equalError := result.equals(ctx, argument[0], nil)
+		if equalError != nil {
+			return nil, equalError
+		}
+		return plasma.NewBool(equal), nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		equal, equalError := result.equals(ctx, argument[0], nil)
+		if equalError != nil {
+			return nil, equalError
+		}
+		return plasma.NewBool(!equal), nil
+	})
+	plasma.define(result.vtable, magic_functions.GreaterThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.compareSequences(ctx, result, argument[0], magic_functions.GreaterThan)
+	})
+	plasma.define(result.vtable, magic_functions.GreaterOrEqualThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.compareSequences(ctx, result, argument[0], magic_functions.GreaterOrEqualThan)
+	})
+	plasma.define(result.vtable, magic_functions.LessThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.compareSequences(ctx, result, argument[0], magic_functions.LessThan)
+	})
+	plasma.define(result.vtable, magic_functions.LessOrEqualThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.compareSequences(ctx, result, argument[0], magic_functions.LessOrEqualThan)
+	})
+	plasma.define(result.vtable, magic_functions.Mul, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			times := argument[0].GetInt64()
+			currentValues := result.GetValues()
+			newValues := make([]*Value, 0, int64(len(currentValues))*times)
+			for i := int64(0); i < times; i++ {
+				for _, value := range currentValues {
+					newValues = append(newValues, value)
 				}
-				rawString = append(rawString, value.String()...)
 			}
-			rawString = append(rawString, ']')
-			return plasma.NewString(rawString), nil
-		}))
-	result.Set(magic_functions.Bytes, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			var rawString []byte
-			for _, value := range result.GetValues() {
-				rawString = append(rawString, byte(value.Int()))
+			return plasma.NewArray(newValues), nil
+		default:
+			return nil, NotOperable
+		}
+	})
+	plasma.define(result.vtable, magic_functions.Length, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(int64(len(result.GetValues()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(len(result.GetValues()) > 0), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s, renderError := plasma.repr(ctx, result)
+		if renderError != nil {
+			return nil, renderError
+		}
+		return plasma.NewString([]byte(s)), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bytes, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		var rawString []byte
+		for _, value := range result.GetValues() {
+			rawString = append(rawString, byte(value.Int()))
+		}
+		rawString = append(rawString, ']')
+		return plasma.NewBytes(rawString), nil
+	})
+	plasma.define(result.vtable, magic_functions.Array, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Tuple, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewTuple(result.GetValues()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Get, []Parameter{param("index")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return result.GetValues()[argument[0].GetInt64()], nil
+		default:
+			return nil, NotIndexable
+		}
+	})
+	plasma.define(result.vtable, magic_functions.Set, []Parameter{param("index"), param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			result.GetValues()[argument[0].GetInt64()] = argument[1]
+			return plasma.none, nil
+		default:
+			return nil, NotIndexable
+		}
+	})
+	plasma.define(result.vtable, magic_functions.Iter, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
+		iter.SetAny(int64(0))
+		plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewBool(iter.GetInt64() < int64(len(result.GetValues()))), nil
+		})
+		plasma.define(iter.vtable, magic_functions.Next, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			currentValues := result.GetValues()
+			index := iter.GetInt64()
+			iter.SetAny(index + 1)
+			if index < int64(len(currentValues)) {
+				return currentValues[index], nil
 			}
-			rawString = append(rawString, ']')
-			return plasma.NewBytes(rawString), nil
-		}))
-	result.Set(magic_functions.Array, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result, nil
-		}))
-	result.Set(magic_functions.Tuple, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewTuple(result.GetValues()), nil
-		}))
-	result.Set(magic_functions.Get, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId:
-				return result.GetValues()[argument[0].GetInt64()], nil
-			default:
-				return nil, NotIndexable
+			return plasma.none, nil
+		})
+		return iter, nil
+	})
+	plasma.define(result.vtable, magic_functions.Append, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		result.SetAny(append(result.GetValues(), argument[0]))
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Clear, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		result.SetAny([]*Value{})
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Index, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		for index, value := range result.GetValues() {
+			if value.Equal(argument[0]) {
+				return plasma.NewInt(int64(index)), nil
 			}
-		}))
-	result.Set(magic_functions.Set, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId:
-				result.GetValues()[argument[0].GetInt64()] = argument[1]
-				return plasma.none, nil
-			default:
-				return nil, NotIndexable
+		}
+		return plasma.NewInt(-1), nil
+	})
+	plasma.define(result.vtable, magic_functions.Pop, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		currentValues := result.GetValues()
+		r := currentValues[len(currentValues)-1]
+		currentValues = currentValues[:len(currentValues)-1]
+		result.SetAny(currentValues)
+		return r, nil
+	})
+	plasma.define(result.vtable, magic_functions.Insert, []Parameter{param("index", IntId), param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		index := argument[0].Int()
+		value := argument[1]
+		currentValues := result.GetValues()
+		newValues := make([]*Value, 0, 1+int64(len(currentValues)))
+		newValues = append(newValues, currentValues[:index]...)
+		newValues = append(newValues, value)
+		newValues = append(newValues, currentValues[index:]...)
+		result.SetAny(newValues)
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Remove, []Parameter{param("index", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		index := argument[0].Int()
+		currentValues := result.GetValues()
+		newValues := make([]*Value, 0, 1+int64(len(currentValues)))
+		newValues = append(newValues, currentValues[:index]...)
+		newValues = append(newValues, currentValues[index+1:]...)
+		result.SetAny(newValues)
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Sort, []Parameter{optional("key"), optional("reverse")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var (
+			key     *Value
+			reverse bool
+		)
+		if len(argument) > 0 {
+			key = argument[0]
+		}
+		if len(argument) > 1 {
+			reverse = argument[1].Bool()
+		}
+		values := append([]*Value{}, result.GetValues()...)
+		sortError := plasma.sortValues(ctx, values, key, reverse)
+		if sortError != nil {
+			return nil, sortError
+		}
+		result.SetAny(values)
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Reverse, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		currentValues := result.GetValues()
+		newValues := make([]*Value, len(currentValues))
+		for index, value := range currentValues {
+			newValues[len(currentValues)-1-index] = value
+		}
+		result.SetAny(newValues)
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Extend, []Parameter{param("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		values, collectError := plasma.collect(ctx, argument[0])
+		if collectError != nil {
+			return nil, collectError
+		}
+		result.SetAny(append(result.GetValues(), values...))
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Count, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var count int64
+		for _, value := range result.GetValues() {
+			equal, equalError := value.equals(ctx, argument[0], nil)
+			if equalError != nil {
+				return nil, equalError
 			}
-		}))
-	result.Set(magic_functions.Iter, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
-			iter.SetAny(int64(0))
-			iter.Set(magic_functions.HasNext, plasma.NewBuiltInFunction(iter.vtable,
-				func(argument ...*Value) (*Value, error) {
-					return plasma.NewBool(iter.GetInt64() < int64(len(result.GetValues()))), nil
-				},
-			))
-			iter.Set(magic_functions.Next, plasma.NewBuiltInFunction(iter.vtable,
-				func(argument ...*Value) (*Value, error) {
-					currentValues := result.GetValues()
-					index := iter.GetInt64()
-					iter.SetAny(index + 1)
-					if index < int64(len(currentValues)) {
-						return currentValues[index], nil
-					}
-					return plasma.none, nil
-				},
-			))
-			return iter, nil
-		}))
-	result.Set(magic_functions.Append, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			result.SetAny(append(result.GetValues(), argument[0]))
-			return plasma.none, nil
-		},
-	))
-	result.Set(magic_functions.Clear, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			result.SetAny([]*Value{})
-			return plasma.none, nil
-		},
-	))
-	result.Set(magic_functions.Index, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			for index, value := range result.GetValues() {
-				if value.Equal(argument[0]) {
-					return plasma.NewInt(int64(index)), nil
-				}
+			if equal {
+				count++
 			}
-			return plasma.NewInt(-1), nil
-		},
-	))
-	result.Set(magic_functions.Pop, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			currentValues := result.GetValues()
-			r := currentValues[len(currentValues)-1]
-			currentValues = currentValues[:len(currentValues)-1]
-			result.SetAny(currentValues)
-			return r, nil
-		},
-	))
-	result.Set(magic_functions.Insert, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			index := argument[0].Int()
-			value := argument[1]
-			currentValues := result.GetValues()
-			newValues := make([]*Value, 0, 1+int64(len(currentValues)))
-			newValues = append(newValues, currentValues[:index]...)
-			newValues = append(newValues, value)
-			newValues = append(newValues, currentValues[index:]...)
-			result.SetAny(newValues)
-			return plasma.none, nil
-		},
-	))
-	result.Set(magic_functions.Remove, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			index := argument[0].Int()
-			currentValues := result.GetValues()
-			newValues := make([]*Value, 0, 1+int64(len(currentValues)))
-			newValues = append(newValues, currentValues[:index]...)
-			newValues = append(newValues, currentValues[index+1:]...)
-			result.SetAny(newValues)
-			return plasma.none, nil
-		},
-	))
+		}
+		return plasma.NewInt(count), nil
+	})
+	plasma.define(result.vtable, magic_functions.BinarySearch, []Parameter{param("value"), optional("key")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var key *Value
+		if len(argument) > 1 {
+			key = argument[1]
+		}
+		index, searchError := plasma.binarySearch(ctx, result.GetValues(), argument[0], key)
+		if searchError != nil {
+			return nil, searchError
+		}
+		return plasma.NewInt(int64(index)), nil
+	})
 	return result
 }
@@ -0,0 +1,265 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+/*
+Integers are stored as int64 and promoted to *big.Int only when an operation overflows.
+Every result goes through NewBigInt, which demotes values back to int64 when they fit,
+so a *big.Int representation always means the number is outside the int64 range.
+*/
+
+var (
+	// MaxIntegerBits bounds the results of ** and <<, the operations able to exhaust the memory with a single call
+	MaxIntegerBits  int64 = 1 << 20
+	IntegerTooLarge       = fmt.Errorf("integer too large")
+)
+
+func (value *Value) isBigInt() bool {
+	_, isBig := value.GetAny().(*big.Int)
+	return isBig
+}
+
+// GetBigInt returns the integer as *big.Int, independently of its internal representation
+func (value *Value) GetBigInt() *big.Int {
+	switch i := value.GetAny().(type) {
+	case *big.Int:
+		return i
+	case int64:
+		return big.NewInt(i)
+	}
+	return big.NewInt(value.Int())
+}
+
+// Int64 returns the integer when it fits in 64 bits, unlike Int it fails with IntegerTooLarge instead of truncating it
+func (value *Value) Int64() (int64, error) {
+	if i, isBig := value.GetAny().(*big.Int); isBig {
+		return 0, fmt.Errorf("%w: %s does not fit in 64 bits", IntegerTooLarge, i)
+	}
+	return value.Int(), nil
+}
+
+func smallIntegers(a, b *Value) (int64, int64, bool) {
+	x, xIsSmall := a.GetAny().(int64)
+	if !xIsSmall {
+		return 0, 0, false
+	}
+	y, yIsSmall := b.GetAny().(int64)
+	return x, y, yIsSmall
+}
+
+func (plasma *Plasma) integerAdd(a, b *Value) *Value {
+	if x, y, small := smallIntegers(a, b); small {
+		if s := x + y; (s > x) == (y > 0) {
+			return plasma.NewInt(s)
+		}
+	}
+	return plasma.NewBigInt(new(big.Int).Add(a.GetBigInt(), b.GetBigInt()))
+}
+
+func (plasma *Plasma) integerSub(a, b *Value) *Value {
+	if x, y, small := smallIntegers(a, b); small {
+		if d := x - y; (d < x) == (y > 0) {
+			return plasma.NewInt(d)
+		}
+	}
+	return plasma.NewBigInt(new(big.Int).Sub(a.GetBigInt(), b.GetBigInt()))
+}
+
+func (plasma *Plasma) integerMul(a, b *Value) *Value {
+	if x, y, small := smallIntegers(a, b); small {
+		if x == 0 || y == 0 {
+			return plasma.NewInt(0)
+		}
+		p := x * y
+		if p/y == x &&
+			!(x == -1 && y == math.MinInt64) &&
+			!(y == -1 && x == math.MinInt64) {
+			return plasma.NewInt(p)
+		}
+	}
+	return plasma.NewBigInt(new(big.Int).Mul(a.GetBigInt(), b.GetBigInt()))
+}
+
+func (plasma *Plasma) integerQuo(a, b *Value) (*Value, error) {
+	if x, y, small := smallIntegers(a, b); small {
+		if y == 0 {
+			return nil, NotOperable
+		}
+		if !(x == math.MinInt64 && y == -1) {
+			return plasma.NewInt(x / y), nil
+		}
+	}
+	divisor := b.GetBigInt()
+	if divisor.Sign() == 0 {
+		return nil, NotOperable
+	}
+	return plasma.NewBigInt(new(big.Int).Quo(a.GetBigInt(), divisor)), nil
+}
+
+func (plasma *Plasma) integerRem(a, b *Value) (*Value, error) {
+	if x, y, small := smallIntegers(a, b); small {
+		if y == 0 {
+			return nil, NotOperable
+		}
+		if y == -1 {
+			return plasma.NewInt(0), nil
+		}
+		return plasma.NewInt(x % y), nil
+	}
+	divisor := b.GetBigInt()
+	if divisor.Sign() == 0 {
+		return nil, NotOperable
+	}
+	return plasma.NewBigInt(new(big.Int).Rem(a.GetBigInt(), divisor)), nil
+}
+
+func (plasma *Plasma) integerPow(a, b *Value) (*Value, error) {
+	exponent := b.GetBigInt()
+	if exponent.Sign() < 0 {
+		return plasma.NewFloat(math.Pow(a.Float(), b.Float())), nil
+	}
+	base := a.GetBigInt()
+	// 0, 1 and -1 never grow, the other bases need at least one more bit per multiplication
+	if bits := int64(base.BitLen()); bits > 1 && exponent.Sign() > 0 {
+		if !exponent.IsInt64() || exponent.Int64() > MaxIntegerBits || bits > MaxIntegerBits/exponent.Int64() {
+			return nil, IntegerTooLarge
+		}
+	}
+	return plasma.NewBigInt(new(big.Int).Exp(base, exponent, nil)), nil
+}
+
+func (plasma *Plasma) integerNegative(a *Value) *Value {
+	if x, small := a.GetAny().(int64); small && x != math.MinInt64 {
+		return plasma.NewInt(-x)
+	}
+	return plasma.NewBigInt(new(big.Int).Neg(a.GetBigInt()))
+}
+
+func (plasma *Plasma) integerNegateBits(a *Value) *Value {
+	if x, small := a.GetAny().(int64); small {
+		return plasma.NewInt(^x)
+	}
+	return plasma.NewBigInt(new(big.Int).Not(a.GetBigInt()))
+}
+
+func (plasma *Plasma) integerOr(a, b *Value) *Value {
+	if x, y, small := smallIntegers(a, b); small {
+		return plasma.NewInt(x | y)
+	}
+	return plasma.NewBigInt(new(big.Int).Or(a.GetBigInt(), b.GetBigInt()))
+}
+
+func (plasma *Plasma) integerXor(a, b *Value) *Value {
+	if x, y, small := smallIntegers(a, b); small {
+		return plasma.NewInt(x ^ y)
+	}
+	return plasma.NewBigInt(new(big.Int).Xor(a.GetBigInt(), b.GetBigInt()))
+}
+
+func (plasma *Plasma) integerAnd(a, b *Value) *Value {
+	if x, y, small := smallIntegers(a, b); small {
+		return plasma.NewInt(x & y)
+	}
+	return plasma.NewBigInt(new(big.Int).And(a.GetBigInt(), b.GetBigInt()))
+}
+
+func (plasma *Plasma) integerLeftShift(a, b *Value) (*Value, error) {
+	if b.isBigInt() || b.GetInt64() < 0 {
+		return nil, NotOperable
+	}
+	shift := b.GetInt64()
+	if x, small := a.GetAny().(int64); small && shift < 63 {
+		if shifted := x << shift; shifted>>shift == x {
+			return plasma.NewInt(shifted), nil
+		}
+	}
+	value := a.GetBigInt()
+	if value.Sign() != 0 && shift > MaxIntegerBits-int64(value.BitLen()) {
+		return nil, IntegerTooLarge
+	}
+	return plasma.NewBigInt(new(big.Int).Lsh(value, uint(shift))), nil
+}
+
+func (plasma *Plasma) integerRightShift(a, b *Value) (*Value, error) {
+	if b.isBigInt() || b.GetInt64() < 0 {
+		return nil, NotOperable
+	}
+	shift := b.GetInt64()
+	if x, small := a.GetAny().(int64); small {
+		return plasma.NewInt(x >> shift), nil
+	}
+	return plasma.NewBigInt(new(big.Int).Rsh(a.GetBigInt(), uint(shift))), nil
+}
+
+func compareIntegers(a, b *Value) int {
+	if x, y, small := smallIntegers(a, b); small {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return a.GetBigInt().Cmp(b.GetBigInt())
+}
+
+// numericEqual compares Int and Float values, big integers are compared exactly against floats
+func numericEqual(a, b *Value) bool {
+	aType, bType := a.TypeId(), b.TypeId()
+	switch {
+	case aType == IntId && bType == IntId:
+		return compareIntegers(a, b) == 0
+	case aType == IntId && a.isBigInt():
+		return bigIntEqualFloat(a.GetBigInt(), b.Float())
+	case bType == IntId && b.isBigInt():
+		return bigIntEqualFloat(b.GetBigInt(), a.Float())
+	}
+	return a.Float() == b.Float()
+}
+
+func bigIntEqualFloat(i *big.Int, f float64) bool {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return false
+	}
+	return new(big.Float).SetInt(i).Cmp(big.NewFloat(f)) == 0
+}
+
+func bigIntToFloat(i *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(i).Float64()
+	return f
+}
+
+// bigIntToBytes encodes the integer as big endian two's complement, using at least 8 bytes
+func bigIntToBytes(i *big.Int) []byte {
+	length := i.BitLen()/8 + 1
+	if length < 8 {
+		length = 8
+	}
+	if i.Sign() >= 0 {
+		return i.FillBytes(make([]byte, length))
+	}
+	modulus := new(big.Int).Lsh(big.NewInt(1), uint(length*8))
+	return modulus.Add(modulus, i).FillBytes(make([]byte, length))
+}
+
+// bigIntFromBytes decodes a big endian two's complement integer of any length
+func bigIntFromBytes(b []byte) *big.Int {
+	result := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		result.Sub(result, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return result
+}
+
+func reverseBytes(b []byte) []byte {
+	result := make([]byte, len(b))
+	for index, c := range b {
+		result[len(b)-1-index] = c
+	}
+	return result
+}
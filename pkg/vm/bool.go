@@ -1,10 +1,13 @@
 package vm
 
-import magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+import (
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+)
 
 func (plasma *Plasma) boolClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.Bool, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewBool(argument[0].Bool()), nil
 	}))
 	return class
@@ -33,73 +36,46 @@ func (plasma *Plasma) NewBool(b bool) *Value {
 	}
 	result := plasma.NewValue(plasma.rootSymbols, BoolId, plasma.bool)
 	result.SetAny(b)
-	result.Set(magic_functions.Not, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(!result.GetBool()), nil
-		},
-	))
-	result.Set(magic_functions.Equal, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case BoolId:
-				return plasma.NewBool(result.GetBool() == argument[0].GetBool()), nil
-			}
-			return plasma.false, nil
-		},
-	))
-	result.Set(magic_functions.NotEqual, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case BoolId:
-				return plasma.NewBool(result.GetBool() != argument[0].GetBool()), nil
-			}
-			return plasma.true, nil
-		},
-	))
-	result.Set(magic_functions.Bool, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result, nil
-		},
-	))
-	result.Set(magic_functions.String, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewString([]byte(result.String())), nil
-		},
-	))
-	result.Set(magic_functions.Int, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			if result.GetBool() {
-				return plasma.NewInt(1), nil
-			}
-			return plasma.NewInt(0), nil
-		},
-	))
-	result.Set(magic_functions.Float, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			if result.GetBool() {
-				return plasma.NewFloat(1), nil
-			}
-			return plasma.NewFloat(0), nil
-		},
-	))
-	result.Set(magic_functions.Bytes, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBytes([]byte(result.String())), nil
-		},
-	))
-	result.Set(magic_functions.Copy, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result, nil
-		},
-	))
+	plasma.define(result.vtable, magic_functions.Not, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(!result.GetBool()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case BoolId:
+			return plasma.NewBool(result.GetBool() == argument[0].GetBool()), nil
+		}
+		return plasma.false, nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case BoolId:
+			return plasma.NewBool(result.GetBool() != argument[0].GetBool()), nil
+		}
+		return plasma.true, nil
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(result.String())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Int, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if result.GetBool() {
+			return plasma.NewInt(1), nil
+		}
+		return plasma.NewInt(0), nil
+	})
+	plasma.define(result.vtable, magic_functions.Float, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if result.GetBool() {
+			return plasma.NewFloat(1), nil
+		}
+		return plasma.NewFloat(0), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bytes, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBytes([]byte(result.String())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Copy, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
 	return result
 }
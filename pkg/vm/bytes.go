@@ -2,14 +2,39 @@ package vm
 
 import (
 	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
 	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
 )
 
 func (plasma *Plasma) bytesClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.Bytes, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewBytes(argument[0].Contents()), nil
 	}))
+	plasma.define(class.vtable, magic_functions.FromHex, []Parameter{param("encoded", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		decoded, decodeError := hex.DecodeString(argument[0].String())
+		if decodeError != nil {
+			return nil, decodeError
+		}
+		return plasma.NewBytes(decoded), nil
+	})
+	plasma.define(class.vtable, magic_functions.FromBase64, []Parameter{param("encoded", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		decoded, decodeError := base64.StdEncoding.DecodeString(argument[0].String())
+		if decodeError != nil {
+			return nil, decodeError
+		}
+		return plasma.NewBytes(decoded), nil
+	})
+	plasma.define(class.vtable, magic_functions.FromBase32, []Parameter{param("encoded", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		decoded, decodeError := base32.StdEncoding.DecodeString(argument[0].String())
+		if decodeError != nil {
+			return nil, decodeError
+		}
+		return plasma.NewBytes(decoded), nil
+	})
 	return class
 }
 
@@ -34,206 +59,174 @@ Upper				upper
 Lower				lower
 Count				count
 Index				Index
+Decode				decode
+Hex					hex
+Base64				base64
+Base32				base32
+Text methods		see textMethods
 */
 func (plasma *Plasma) NewBytes(contents []byte) *Value {
 	result := plasma.NewValue(plasma.rootSymbols, BytesId, plasma.bytes)
 	result.SetAny(contents)
-	result.Set(magic_functions.In, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case BytesId:
-				return plasma.NewBool(bytes.Contains(result.GetBytes(), argument[0].GetBytes())), nil
-			case IntId:
-				i := argument[0].GetInt64()
-				for _, b := range result.GetBytes() {
-					if int64(b) == i {
-						return plasma.true, nil
-					}
+	plasma.define(result.vtable, magic_functions.In, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case BytesId:
+			return plasma.NewBool(bytes.Contains(result.GetBytes(), argument[0].GetBytes())), nil
+		case IntId:
+			i := argument[0].GetInt64()
+			for _, b := range result.GetBytes() {
+				if int64(b) == i {
+					return plasma.true, nil
 				}
-				return plasma.false, nil
 			}
 			return plasma.false, nil
-		},
-	))
-	result.Set(magic_functions.Equal, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(result.Equal(argument[0])), nil
-		},
-	))
-	result.Set(magic_functions.NotEqual, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(!result.Equal(argument[0])), nil
-		},
-	))
-	result.Set(magic_functions.Add, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case BytesId:
-				s := result.GetBytes()
-				otherS := argument[0].GetBytes()
-				newString := make([]byte, 0, len(s)+len(otherS))
-				newString = append(newString, s...)
-				newString = append(newString, otherS...)
-				return plasma.NewBytes(newString), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Mul, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId:
-				s := result.GetBytes()
-				times := argument[0].GetInt64()
-				return plasma.NewBytes(bytes.Repeat(s, int(times))), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Length, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewInt(int64(len(result.GetBytes()))), nil
-		},
-	))
-	result.Set(magic_functions.Bool, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(len(result.GetBytes()) > 0), nil
-		},
-	))
-	result.Set(magic_functions.String, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewString(result.GetBytes()), nil
-		},
-	))
-	result.Set(magic_functions.Bytes, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result, nil
-		},
-	))
-	result.Set(magic_functions.Array, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
+		}
+		return plasma.false, nil
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result.Equal(argument[0])), nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(!result.Equal(argument[0])), nil
+	})
+	plasma.define(result.vtable, magic_functions.Add, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case BytesId:
 			s := result.GetBytes()
-			values := make([]*Value, 0, len(s))
-			for _, b := range s {
-				values = append(values, plasma.NewInt(int64(b)))
+			otherS := argument[0].GetBytes()
+			newString := make([]byte, 0, len(s)+len(otherS))
+			newString = append(newString, s...)
+			newString = append(newString, otherS...)
+			return plasma.NewBytes(newString), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Mul, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			repeated, repeatError := repeat(result.GetBytes(), argument[0])
+			if repeatError != nil {
+				return nil, repeatError
 			}
-			return plasma.NewArray(values), nil
-		},
-	))
-	result.Set(magic_functions.Tuple, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
+			return plasma.NewBytes(repeated), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Length, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(int64(len(result.GetBytes()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(len(result.GetBytes()) > 0), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString(result.GetBytes()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bytes, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Array, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s := result.GetBytes()
+		values := make([]*Value, 0, len(s))
+		for _, b := range s {
+			values = append(values, plasma.NewInt(int64(b)))
+		}
+		return plasma.NewArray(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Tuple, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s := result.GetBytes()
+		values := make([]*Value, 0, len(s))
+		for _, b := range s {
+			values = append(values, plasma.NewInt(int64(b)))
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Get, []Parameter{param("index")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
 			s := result.GetBytes()
-			values := make([]*Value, 0, len(s))
-			for _, b := range s {
-				values = append(values, plasma.NewInt(int64(b)))
-			}
-			return plasma.NewTuple(values), nil
-		},
-	))
-	result.Set(magic_functions.Get, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId:
-				s := result.GetBytes()
-				index := argument[0].GetInt64()
-				return plasma.NewInt(int64(s[index])), nil
-			case TupleId:
-				s := result.GetBytes()
-				values := argument[0].GetValues()
-				startIndex := values[0].GetInt64()
-				endIndex := values[1].GetInt64()
-				return plasma.NewBytes(s[startIndex:endIndex]), nil
-			}
-			return nil, NotIndexable
-		},
-	))
-	result.Set(magic_functions.Copy, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
+			index := argument[0].GetInt64()
+			return plasma.NewInt(int64(s[index])), nil
+		case TupleId:
 			s := result.GetBytes()
-			newS := make([]byte, len(s))
-			copy(newS, s)
-			return plasma.NewBytes(newS), nil
-		},
-	))
-	result.Set(magic_functions.Iter, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
-			iter.SetAny(int64(0))
-			iter.Set(magic_functions.HasNext, plasma.NewBuiltInFunction(iter.vtable,
-				func(argument ...*Value) (*Value, error) {
-					return plasma.NewBool(iter.GetInt64() < int64(len(result.GetBytes()))), nil
-				},
-			))
-			iter.Set(magic_functions.Next, plasma.NewBuiltInFunction(iter.vtable,
-				func(argument ...*Value) (*Value, error) {
-					currentBytes := result.GetBytes()
-					index := iter.GetInt64()
-					iter.SetAny(index + 1)
-					if index < int64(len(currentBytes)) {
-						return plasma.NewBytes([]byte{currentBytes[index]}), nil
-					}
-					return plasma.none, nil
-				},
-			))
-			return iter, nil
-		},
-	))
-	result.Set(magic_functions.Join, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			values := argument[0].Values()
-			valuesBytes := make([][]byte, 0, len(values))
-			for _, value := range values {
-				valuesBytes = append(valuesBytes, []byte(value.String()))
-			}
-			return plasma.NewBytes(bytes.Join(valuesBytes, []byte(result.String()))), nil
-		},
-	))
-	result.Set(magic_functions.Split, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			sep := argument[0].String()
-			splitted := bytes.Split(result.GetBytes(), []byte(sep))
-			values := make([]*Value, 0, len(splitted))
-			for _, b := range splitted {
-				values = append(values, plasma.NewBytes(b))
+			values := argument[0].GetValues()
+			startIndex := values[0].GetInt64()
+			endIndex := values[1].GetInt64()
+			return plasma.NewBytes(s[startIndex:endIndex]), nil
+		}
+		return nil, NotIndexable
+	})
+	plasma.define(result.vtable, magic_functions.Copy, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s := result.GetBytes()
+		newS := make([]byte, len(s))
+		copy(newS, s)
+		return plasma.NewBytes(newS), nil
+	})
+	plasma.define(result.vtable, magic_functions.Iter, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
+		iter.SetAny(int64(0))
+		plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewBool(iter.GetInt64() < int64(len(result.GetBytes()))), nil
+		})
+		plasma.define(iter.vtable, magic_functions.Next, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			currentBytes := result.GetBytes()
+			index := iter.GetInt64()
+			iter.SetAny(index + 1)
+			if index < int64(len(currentBytes)) {
+				return plasma.NewBytes([]byte{currentBytes[index]}), nil
 			}
-			return plasma.NewTuple(values), nil
-		},
-	))
-	result.Set(magic_functions.Upper, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBytes(bytes.ToUpper(result.GetBytes())), nil
-		},
-	))
-	result.Set(magic_functions.Lower, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBytes(bytes.ToLower(result.GetBytes())), nil
-		},
-	))
-	result.Set(magic_functions.Count, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			sep := argument[0].String()
-			return plasma.NewInt(int64(bytes.Count(result.GetBytes(), []byte(sep)))), nil
-		},
-	))
-	result.Set(magic_functions.Index, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			sep := argument[0].String()
-			return plasma.NewInt(int64(bytes.Index(result.GetBytes(), []byte(sep)))), nil
-		},
-	))
+			return plasma.none, nil
+		})
+		return iter, nil
+	})
+	plasma.define(result.vtable, magic_functions.Join, []Parameter{param("values", sequenceTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		values := argument[0].Values()
+		valuesBytes := make([][]byte, 0, len(values))
+		for _, value := range values {
+			valuesBytes = append(valuesBytes, []byte(value.String()))
+		}
+		return plasma.NewBytes(bytes.Join(valuesBytes, []byte(result.String()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Split, []Parameter{param("separator", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		sep := argument[0].String()
+		splitted := bytes.Split(result.GetBytes(), []byte(sep))
+		values := make([]*Value, 0, len(splitted))
+		for _, b := range splitted {
+			values = append(values, plasma.NewBytes(b))
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Upper, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBytes(bytes.ToUpper(result.GetBytes())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Lower, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBytes(bytes.ToLower(result.GetBytes())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Count, []Parameter{param("separator", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		sep := argument[0].String()
+		return plasma.NewInt(int64(bytes.Count(result.GetBytes(), []byte(sep)))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Index, []Parameter{param("separator", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		sep := argument[0].String()
+		return plasma.NewInt(int64(bytes.Index(result.GetBytes(), []byte(sep)))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Decode, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		b := result.GetBytes()
+		if validError := validUTF8(b); validError != nil {
+			return nil, validError
+		}
+		decoded := make([]byte, len(b))
+		copy(decoded, b)
+		return plasma.NewString(decoded), nil
+	})
+	plasma.define(result.vtable, magic_functions.Hex, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(hex.EncodeToString(result.GetBytes()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Base64, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(base64.StdEncoding.EncodeToString(result.GetBytes()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Base32, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(base32.StdEncoding.EncodeToString(result.GetBytes()))), nil
+	})
+	plasma.textMethods(result, plasma.NewBytes, false)
 	return result
 }
@@ -0,0 +1,197 @@
+package vm
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/shoriwe/gplasma/pkg/bytecode/opcodes"
+	"github.com/shoriwe/gplasma/pkg/common"
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+)
+
+var (
+	ReadOnlyProperty = fmt.Errorf("read only property")
+	ObjectNotCreated = fmt.Errorf("object not created")
+)
+
+type (
+	// Method receives the object it was called on, its Go value is available with self.GetAny()
+	Method   func(self *Value, argument ...*Value) (*Value, error)
+	property struct {
+		get func(self *Value) (*Value, error)
+		set func(self, value *Value) error
+	}
+	builderMethod struct {
+		name   string
+		method Method
+	}
+	/*
+		ClassBuilder defines a Plasma class backed by Go values. The class is a regular script class,
+		so it can be subclassed, its body only installs the methods and properties on the new object.
+		Subclasses redefining __init__ create the Go value calling Class.__init__(self, ...).
+	*/
+	ClassBuilder struct {
+		plasma      *Plasma
+		name        string
+		constructor func(argument ...*Value) (any, error)
+		methods     []builderMethod
+		properties  map[string]*property
+	}
+)
+
+func (plasma *Plasma) NewClassBuilder(name string) *ClassBuilder {
+	return &ClassBuilder{
+		plasma:     plasma,
+		name:       name,
+		properties: map[string]*property{},
+	}
+}
+
+// Constructor sets the function creating the Go value held by the objects, it is exposed as __init__
+func (builder *ClassBuilder) Constructor(constructor func(argument ...*Value) (any, error)) *ClassBuilder {
+	builder.constructor = constructor
+	return builder
+}
+
+// Method adds a method, magic functions like __string__ or __equal__ are defined the same way
+func (builder *ClassBuilder) Method(name string, method Method) *ClassBuilder {
+	builder.methods = append(builder.methods, builderMethod{name: name, method: method})
+	return builder
+}
+
+// Property maps an attribute to a getter and a setter, properties without setter are read only
+func (builder *ClassBuilder) Property(name string, get func(self *Value) (*Value, error), set func(self, value *Value) error) *ClassBuilder {
+	builder.properties[name] = &property{get: get, set: set}
+	return builder
+}
+
+func (builder *ClassBuilder) init(self *Value, argument ...*Value) (*Value, error) {
+	if builder.constructor == nil {
+		if len(argument) != 0 {
+			return nil, &ArgumentError{Signature: Signature{Name: builder.name}, Received: len(argument)}
+		}
+		return builder.plasma.none, nil
+	}
+	goValue, constructError := builder.constructor(argument...)
+	if constructError != nil {
+		return nil, constructError
+	}
+	self.SetAny(goValue)
+	return builder.plasma.none, nil
+}
+
+// created fails when the method is called on an object whose __init__ never created the Go value
+func (builder *ClassBuilder) created(self *Value) error {
+	if self.GetAny() == nil {
+		return fmt.Errorf("%w: %s.__init__ was not called", ObjectNotCreated, builder.name)
+	}
+	return nil
+}
+
+func (builder *ClassBuilder) isInstance(value, class *Value) bool {
+	return value.TypeId() == ValueId && value.GetClass().TypeId() == ClassId && value.GetClass().Implements(class)
+}
+
+func (builder *ClassBuilder) bind(self *Value, method Method) *Value {
+	return builder.plasma.NewBuiltInFunction(self.vtable,
+		func(argument ...*Value) (*Value, error) {
+			if createdError := builder.created(self); createdError != nil {
+				return nil, createdError
+			}
+			return method(self, argument...)
+		},
+	)
+}
+
+func (builder *ClassBuilder) prepare(self *Value) {
+	plasma := builder.plasma
+	self.Set(magic_functions.Init, plasma.NewBuiltInFunction(self.vtable,
+		func(argument ...*Value) (*Value, error) {
+			return builder.init(self, argument...)
+		},
+	))
+	for _, m := range builder.methods {
+		self.Set(m.name, builder.bind(self, m.method))
+	}
+	if len(builder.properties) == 0 {
+		return
+	}
+	properties := make(map[string]*property, len(builder.properties))
+	for name, p := range builder.properties {
+		p := p
+		properties[name] = &property{
+			get: func(self *Value) (*Value, error) {
+				if createdError := builder.created(self); createdError != nil {
+					return nil, createdError
+				}
+				return p.get(self)
+			},
+			set: func(self, value *Value) error {
+				if p.set == nil {
+					return fmt.Errorf("%w: %s.%s", ReadOnlyProperty, builder.name, name)
+				}
+				if createdError := builder.created(self); createdError != nil {
+					return createdError
+				}
+				return p.set(self, value)
+			},
+		}
+	}
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.properties = properties
+	atomic.StoreUint32(&self.hasProperties, 1)
+}
+
+/*
+Build creates the class. Its methods are also available unbound from the class, receiving the object
+as first argument, Class.__init__(self, ...) is the way subclasses create the Go value.
+The body of the class calls a built-in registered in the root symbols under a name scripts can't
+write, this way it is still found when the body runs as part of a subclass defined elsewhere.
+*/
+func (builder *ClassBuilder) Build() *Value {
+	plasma := builder.plasma
+	class := plasma.NewValue(plasma.rootSymbols, ClassId, plasma.class)
+	prepareName := fmt.Sprintf("<%s %p>", builder.name, class)
+	plasma.rootSymbols.Set(prepareName, plasma.NewBuiltInFunction(plasma.rootSymbols,
+		func(argument ...*Value) (*Value, error) {
+			builder.prepare(argument[0])
+			return plasma.none, nil
+		},
+	))
+	// prepare(self)
+	var body []byte
+	body = append(body, opcodes.Identifier)
+	body = append(body, common.IntToBytes(len(special_symbols.Self))...)
+	body = append(body, special_symbols.Self...)
+	body = append(body, opcodes.Push)
+	body = append(body, opcodes.Identifier)
+	body = append(body, common.IntToBytes(len(prepareName))...)
+	body = append(body, prepareName...)
+	body = append(body, opcodes.Push)
+	body = append(body, opcodes.Call)
+	body = append(body, common.IntToBytes(1)...)
+	class.SetAny(&ClassInfo{
+		Bytecode: body,
+		cache:    newInlineCache(),
+	})
+	unbound := append([]builderMethod{{name: magic_functions.Init, method: builder.init}}, builder.methods...)
+	for _, m := range unbound {
+		m := m
+		class.Set(m.name, plasma.NewBuiltInFunction(class.vtable,
+			func(argument ...*Value) (*Value, error) {
+				if len(argument) == 0 || !builder.isInstance(argument[0], class) {
+					return nil, fmt.Errorf("%w: %s.%s expects a %s object as first argument", NotOperable, builder.name, m.name, builder.name)
+				}
+				if m.name != magic_functions.Init {
+					if createdError := builder.created(argument[0]); createdError != nil {
+						return nil, createdError
+					}
+				}
+				return m.method(argument[0], argument[1:]...)
+			},
+		))
+	}
+	return class
+}
@@ -1,11 +1,14 @@
 package vm
 
-import magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+import (
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+)
 
 func (plasma *Plasma) metaClass() *Value {
 	plasma.class = plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
 	plasma.class.class = plasma.class
-	plasma.class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	plasma.class.SetAny(constructor(special_symbols.Class, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewClass(), nil
 	}))
 	return plasma.class
@@ -18,17 +21,11 @@ NotEqual            __not_equal__
 */
 func (plasma *Plasma) NewClass() *Value {
 	result := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	result.Set(magic_functions.Equal, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(result == argument[0]), nil
-		},
-	))
-	result.Set(magic_functions.NotEqual, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(result != argument[0]), nil
-		},
-	))
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result == argument[0]), nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result != argument[0]), nil
+	})
 	return result
 }
@@ -0,0 +1,114 @@
+package vm
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the time source of the time module, hosts can replace it with SetClock to get deterministic scripts
+type Clock interface {
+	Now() time.Time
+	// Monotonic returns the time elapsed since an arbitrary fixed point
+	Monotonic() time.Duration
+	// After is used by sleep, release is called when the execution is stopped before ready fires
+	After(d time.Duration) (ready <-chan time.Time, release func() bool)
+}
+
+type realClock struct {
+	start time.Time
+}
+
+func (clock *realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (clock *realClock) Monotonic() time.Duration {
+	return time.Since(clock.start)
+}
+
+func (clock *realClock) After(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := time.NewTimer(d)
+	return timer.C, timer.Stop
+}
+
+// FakeClock only moves when advanced, sleeping advances it instantly
+type FakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	elapsed time.Duration
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (clock *FakeClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+func (clock *FakeClock) Monotonic() time.Duration {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.elapsed
+}
+
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = clock.now.Add(d)
+	clock.elapsed += d
+}
+
+func (clock *FakeClock) After(d time.Duration) (<-chan time.Time, func() bool) {
+	clock.Advance(d)
+	ready := make(chan time.Time, 1)
+	ready <- clock.Now()
+	return ready, func() bool { return false }
+}
+
+func (plasma *Plasma) SetClock(clock Clock) {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	plasma.clock = clock
+}
+
+func (plasma *Plasma) Clock() Clock {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	return plasma.clock
+}
+
+/*
+waitRequest is returned as error by built-in functions that need to block.
+The interpreter performs the wait itself, so it can be interrupted by the stop channel
+of the running execution.
+*/
+type waitRequest struct {
+	duration time.Duration
+	result   *Value
+}
+
+func (request *waitRequest) Error() string {
+	return "unhandled wait request"
+}
+
+// wait blocks until the request is done, it returns false when the execution in ctx was stopped before.
+// Without ctx the wait can't be interrupted
+func (plasma *Plasma) wait(ctx *context, request *waitRequest) (*Value, bool) {
+	var stop chan struct{}
+	if ctx != nil {
+		stop = ctx.stop
+	}
+	ready, release := plasma.Clock().After(request.duration)
+	select {
+	case <-ready:
+		return request.result, true
+	case signal := <-stop:
+		release()
+		// Put the signal back so the execution loop stops right after the call
+		signalStop(stop, signal)
+		return request.result, false
+	}
+}
@@ -0,0 +1,44 @@
+package vm
+
+import magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+
+/*
+compareSequences orders arrays and tuples lexicographically.
+The first pair of elements that are not equal decides the result through its own comparison
+method (operator), when one sequence is a prefix of the other the shorter one is the lesser.
+*/
+func (plasma *Plasma) compareSequences(ctx *context, sequence, other *Value, operator string) (*Value, error) {
+	if sequence.TypeId() != other.TypeId() {
+		return nil, NotComparable
+	}
+	values, otherValues := sequence.GetValues(), other.GetValues()
+	for index := 0; index < len(values) && index < len(otherValues); index++ {
+		equal, equalError := values[index].equals(ctx, otherValues[index], nil)
+		if equalError != nil {
+			return nil, equalError
+		}
+		if equal {
+			continue
+		}
+		method, getError := values[index].Get(operator)
+		if getError != nil {
+			return nil, NotComparable
+		}
+		return plasma.call(ctx, method, otherValues[index])
+	}
+	return plasma.NewBool(compareLengths(len(values), len(otherValues), operator)), nil
+}
+
+func compareLengths(length, otherLength int, operator string) bool {
+	switch operator {
+	case magic_functions.LessThan:
+		return length < otherLength
+	case magic_functions.LessOrEqualThan:
+		return length <= otherLength
+	case magic_functions.GreaterThan:
+		return length > otherLength
+	case magic_functions.GreaterOrEqualThan:
+		return length >= otherLength
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package vm
 
 import (
+	"fmt"
+
 	"github.com/shoriwe/gplasma/pkg/common"
 )
 
@@ -9,6 +11,11 @@ type (
 		bytecode []byte
 		rip      int64
 		onExit   *common.ListStack[[]byte]
+		cache    *inlineCache
+		// callee is only set when tracing, so the return of the code can be reported
+		callee *Value
+		// sharedSymbols is set when the code has no scope of its own, see pushSharedCode
+		sharedSymbols bool
 	}
 	context struct {
 		result         chan *Value
@@ -18,6 +25,10 @@ type (
 		stack          *common.ListStack[*Value]
 		register       *Value
 		currentSymbols *Symbols
+		exit           *ExitError
+		tracer         Tracer
+		// renderer is set while a script __string__ or __repr__ runs, see newRenderer
+		renderer *renderer
 	}
 )
 
@@ -33,12 +44,22 @@ func (ctx *context) hasNext() bool {
 	return false
 }
 
+// unwind ends every running code, their deferred code still runs while they are popped
+func (ctx *context) unwind(exit *ExitError) {
+	ctx.exit = exit
+	for node := ctx.code.Top; node != nil; node = node.Next {
+		ctxCode := node.Value.(*contextCode)
+		ctxCode.rip = int64(len(ctxCode.bytecode))
+	}
+}
+
 func (plasma *Plasma) newContext(bytecode []byte) *context {
 	codeStack := &common.ListStack[*contextCode]{}
 	codeStack.Push(&contextCode{
 		bytecode: bytecode,
 		rip:      0,
 		onExit:   &common.ListStack[[]byte]{},
+		cache:    newInlineCache(),
 	})
 	return &context{
 		result:         nil,
@@ -48,5 +69,22 @@ func (plasma *Plasma) newContext(bytecode []byte) *context {
 		stack:          &common.ListStack[*Value]{},
 		register:       nil,
 		currentSymbols: plasma.rootSymbols,
+		tracer:         plasma.Tracer(),
+	}
+}
+
+// signalStop puts back a consumed stop signal, unless the host already sent another one
+func signalStop(stop chan struct{}, signal struct{}) {
+	select {
+	case stop <- signal:
+	default:
+	}
+}
+
+func (ctx *context) traceError(recovered any) {
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("%v", recovered)
 	}
+	ctx.tracer.Error(err)
 }
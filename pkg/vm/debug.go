@@ -1,6 +1,34 @@
 package vm
 
-import "fmt"
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+type InlineCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+func (stats InlineCacheStats) HitRatio() float64 {
+	total := stats.Hits + stats.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(total)
+}
+
+func (plasma *Plasma) InlineCacheStats() InlineCacheStats {
+	return InlineCacheStats{
+		Hits:   atomic.LoadUint64(&plasma.cacheHits),
+		Misses: atomic.LoadUint64(&plasma.cacheMisses),
+	}
+}
+
+func (plasma *Plasma) ResetInlineCacheStats() {
+	atomic.StoreUint64(&plasma.cacheHits, 0)
+	atomic.StoreUint64(&plasma.cacheMisses, 0)
+}
 
 func (plasma *Plasma) printStack(ctx *context) {
 	current := ctx.stack.Top
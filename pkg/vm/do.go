@@ -1,38 +1,53 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
 	"github.com/shoriwe/gplasma/pkg/bytecode/opcodes"
 	"github.com/shoriwe/gplasma/pkg/common"
 	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
 	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+	"math/big"
+	"sync/atomic"
 )
 
-func (ctx *context) pushCode(bytecode []byte) {
+func (ctx *context) pushCode(bytecode []byte, cache *inlineCache) {
 	ctx.code.Push(
 		&contextCode{
 			bytecode: bytecode,
 			rip:      0,
 			onExit:   &common.ListStack[[]byte]{},
+			cache:    cache,
 		},
 	)
 }
+
+// pushSharedCode pushes code running in the symbols of the code below it, like the deferred code
+func (ctx *context) pushSharedCode(bytecode []byte, cache *inlineCache) {
+	ctx.pushCode(bytecode, cache)
+	ctx.code.Peek().sharedSymbols = true
+}
+
 func (ctx *context) popCode() {
 	// If there is defer code
 	if ctxCode := ctx.code.Peek(); ctxCode.onExit.HasNext() {
 		ctxCode.rip = int64(len(ctxCode.bytecode)) + 1
 		if ctx.register != nil {
 			ctx.stack.Push(ctx.register)
-			ctx.pushCode([]byte{opcodes.Return})
-			ctx.currentSymbols = NewSymbols(ctx.currentSymbols)
+			ctx.pushSharedCode([]byte{opcodes.Return}, nil)
 		}
 		for ctxCode.onExit.HasNext() {
-			ctx.pushCode(ctxCode.onExit.Pop())
-			ctx.currentSymbols = NewSymbols(ctx.currentSymbols)
+			ctx.pushSharedCode(ctxCode.onExit.Pop(), newInlineCache())
 		}
 		return
 	}
-	ctx.code.Pop()
+	popped := ctx.code.Pop()
+	if ctx.tracer != nil && popped.callee != nil {
+		ctx.tracer.Return(popped.callee, ctx.register)
+	}
+	if popped.sharedSymbols {
+		return
+	}
 	if ctx.currentSymbols.call != nil {
 		ctx.currentSymbols = ctx.currentSymbols.call
 	} else {
@@ -60,8 +75,9 @@ func (plasma *Plasma) prepareClassInitCode(classInfo *ClassInfo) {
 func (plasma *Plasma) do(ctx *context) {
 	ctxCode := ctx.code.Peek()
 	instruction := ctxCode.bytecode[ctxCode.rip]
-	// fmt.Println(opcodes.OpCodes[instruction])
-	// plasma.printStack(ctx)
+	if ctx.tracer != nil {
+		ctx.tracer.Step(instruction, ctxCode.rip)
+	}
 	switch instruction {
 	case opcodes.Push:
 		ctxCode.rip++
@@ -76,7 +92,11 @@ func (plasma *Plasma) do(ctx *context) {
 		symbol := string(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+symbolLength])
 		// fmt.Println(symbol)
 		ctxCode.rip += symbolLength
-		ctx.currentSymbols.Set(symbol, ctx.stack.Pop())
+		value := ctx.stack.Pop()
+		ctx.currentSymbols.Set(symbol, value)
+		if ctx.tracer != nil {
+			ctx.tracer.SetSymbol(symbol, value)
+		}
 	case opcodes.SelectorAssign:
 		ctxCode.rip++
 		symbolLength := common.BytesToInt(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+8])
@@ -84,7 +104,9 @@ func (plasma *Plasma) do(ctx *context) {
 		symbol := string(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+symbolLength])
 		ctxCode.rip += symbolLength
 		selector := ctx.stack.Pop()
-		selector.Set(symbol, ctx.stack.Pop())
+		if assignError := selector.Assign(symbol, ctx.stack.Pop()); assignError != nil {
+			panic(assignError)
+		}
 	case opcodes.Label:
 		ctxCode.rip += 9 // OP + Label
 	case opcodes.Jump:
@@ -116,6 +138,9 @@ func (plasma *Plasma) do(ctx *context) {
 		symbol := string(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+symbolLength])
 		ctxCode.rip += symbolLength
 		selector := ctx.stack.Pop()
+		if selector.isFrozen() {
+			panic(FrozenValue)
+		}
 		delError := selector.Del(symbol)
 		if delError != nil {
 			panic(delError)
@@ -146,6 +171,7 @@ func (plasma *Plasma) do(ctx *context) {
 		funcInfo := FuncInfo{
 			Arguments: arguments,
 			Bytecode:  bytecode,
+			cache:     newInlineCache(),
 		}
 		funcObject := plasma.NewValue(ctx.currentSymbols, FunctionId, plasma.function)
 		funcObject.SetAny(funcInfo)
@@ -166,6 +192,7 @@ func (plasma *Plasma) do(ctx *context) {
 		classInfo := &ClassInfo{
 			Bases:    bases,
 			Bytecode: body,
+			cache:    newInlineCache(),
 		}
 		classObject := plasma.NewValue(ctx.currentSymbols, ClassId, plasma.class)
 		classObject.SetAny(classInfo)
@@ -181,16 +208,40 @@ func (plasma *Plasma) do(ctx *context) {
 		}
 		var callError error
 		tries := 0
+		callee := function
+		if ctx.tracer != nil {
+			ctx.tracer.Call(callee, arguments)
+		}
 	doCall:
 		if tries == MaxDoCallSearch {
 			panic("infinite nested __call__")
 		}
 		switch function.TypeId() {
 		case BuiltInFunctionId, BuiltInClassId:
-			ctx.register, callError = function.Call(arguments...)
+			ctx.register, callError = function.call(ctx, arguments...)
+			// Go functions may wrap the special errors, like fmt.Errorf("...: %w", exitError)
+			var (
+				request   *waitRequest
+				exitError *ExitError
+			)
+			switch {
+			case errors.As(callError, &request):
+				ctx.register, _ = plasma.wait(ctx, request)
+				callError = nil
+			case errors.As(callError, &exitError):
+				ctx.unwind(exitError)
+				ctx.register, callError = plasma.none, nil
+			}
+			if errors.Is(callError, ExecutionStopped) {
+				// The stop signal is still pending, the execution loop ends before the next instruction
+				ctx.register, callError = plasma.none, nil
+			}
 			if callError != nil {
 				panic(callError)
 			}
+			if ctx.tracer != nil {
+				ctx.tracer.Return(callee, ctx.register)
+			}
 		case FunctionId:
 			funcInfo := function.GetFuncInfo()
 			// Push new symbol table based on the function
@@ -205,7 +256,10 @@ func (plasma *Plasma) do(ctx *context) {
 				ctx.currentSymbols.Set(argument, arguments[index])
 			}
 			// Push code
-			ctx.pushCode(funcInfo.Bytecode)
+			ctx.pushCode(funcInfo.Bytecode, funcInfo.cache)
+			if ctx.tracer != nil {
+				ctx.code.Peek().callee = callee
+			}
 		case ClassId:
 			classInfo := function.GetClassInfo()
 			if !classInfo.prepared {
@@ -233,7 +287,10 @@ func (plasma *Plasma) do(ctx *context) {
 			// Inject pop object to register
 			classCode = append(classCode, opcodes.Pop)
 			// Load code
-			ctx.pushCode(classCode)
+			ctx.pushCode(classCode, classInfo.cache)
+			if ctx.tracer != nil {
+				ctx.code.Peek().callee = callee
+			}
 			newSymbols := object.vtable
 			newSymbols.call = ctx.currentSymbols
 			ctx.currentSymbols = newSymbols
@@ -268,11 +325,15 @@ func (plasma *Plasma) do(ctx *context) {
 		ctxCode.rip++
 		numberOfValues := common.BytesToInt(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+8])
 		ctxCode.rip += 8
-		hash := plasma.NewInternalHash()
+		keys := make([]*Value, numberOfValues)
+		values := make([]*Value, numberOfValues)
 		for i := numberOfValues - 1; i >= 0; i-- {
-			key := ctx.stack.Pop()
-			value := ctx.stack.Pop()
-			setError := hash.Set(key, value)
+			keys[i] = ctx.stack.Pop()
+			values[i] = ctx.stack.Pop()
+		}
+		hash := plasma.NewInternalHash()
+		for index, key := range keys {
+			setError := hash.set(ctx, key, values[index])
 			if setError != nil {
 				panic(setError)
 			}
@@ -290,11 +351,24 @@ func (plasma *Plasma) do(ctx *context) {
 		if getError != nil {
 			panic(getError)
 		}
+		if ctx.tracer != nil {
+			ctx.tracer.GetSymbol(symbol, ctx.register)
+		}
 	case opcodes.Integer:
 		ctxCode.rip++
 		value := common.BytesToInt(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+8])
 		ctxCode.rip += 8
 		ctx.register = plasma.NewInt(value)
+	case opcodes.BigInteger:
+		ctxCode.rip++
+		numberLength := common.BytesToInt(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+8])
+		ctxCode.rip += 8
+		value, ok := new(big.Int).SetString(string(ctxCode.bytecode[ctxCode.rip:ctxCode.rip+numberLength]), 10)
+		if !ok {
+			panic("invalid big integer literal")
+		}
+		ctxCode.rip += numberLength
+		ctx.register = plasma.NewBigInt(value)
 	case opcodes.Float:
 		ctxCode.rip++
 		value := common.BytesToFloat(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+8])
@@ -324,18 +398,27 @@ func (plasma *Plasma) do(ctx *context) {
 		ctxCode.rip++
 		ctx.register = plasma.none
 	case opcodes.Selector:
+		offset := ctxCode.rip
 		ctxCode.rip++
 		symbolLength := common.BytesToInt(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+8])
 		ctxCode.rip += 8
+		selector := ctx.stack.Pop()
+		if cached, hit := ctxCode.cache.lookup(offset, selector); hit {
+			ctxCode.rip += symbolLength
+			atomic.AddUint64(&plasma.cacheHits, 1)
+			ctx.register = cached
+			break
+		}
 		symbol := string(ctxCode.bytecode[ctxCode.rip : ctxCode.rip+symbolLength])
 		ctxCode.rip += symbolLength
 		// fmt.Println(symbol)
-		selector := ctx.stack.Pop()
 		var getError error
 		ctx.register, getError = selector.Get(symbol)
 		if getError != nil {
 			panic(getError)
 		}
+		atomic.AddUint64(&plasma.cacheMisses, 1)
+		ctxCode.cache.store(offset, selector, symbol, ctx.register)
 	case opcodes.Super:
 		break // TODO: Implement me!
 	default:
@@ -0,0 +1,223 @@
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"unicode"
+)
+
+var (
+	InvalidPackFormat = fmt.Errorf("invalid pack format")
+	PackOutOfRange    = fmt.Errorf("value out of range")
+	NotEnoughData     = fmt.Errorf("not enough data")
+)
+
+type packItem struct {
+	code  byte
+	count int
+}
+
+/*
+parsePackFormat reads the layout used by pack and unpack.
+The first character optionally selects the byte order: '<' little endian, '>' or '!' big endian (default).
+Each code may be preceded by a repeat count, spaces are ignored:
+b B		int8 uint8
+h H		int16 uint16
+i I		int32 uint32
+q Q		int64 uint64
+f d		float32 float64
+s		UTF-8 string prefixed by its length as uint32
+*/
+func parsePackFormat(format string) (binary.ByteOrder, []packItem, error) {
+	var order binary.ByteOrder = binary.BigEndian
+	if len(format) > 0 {
+		switch format[0] {
+		case '<':
+			order = binary.LittleEndian
+			format = format[1:]
+		case '>', '!':
+			format = format[1:]
+		}
+	}
+	var items []packItem
+	count := -1
+	for index := 0; index < len(format); index++ {
+		c := format[index]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			if count >= 0 {
+				return nil, nil, fmt.Errorf("%w: repeat count without code at %d", InvalidPackFormat, index)
+			}
+		case '0' <= c && c <= '9':
+			if count < 0 {
+				count = 0
+			}
+			count = count*10 + int(c-'0')
+		case packSize(c) >= 0:
+			if count < 0 {
+				count = 1
+			}
+			items = append(items, packItem{code: c, count: count})
+			count = -1
+		default:
+			return nil, nil, fmt.Errorf("%w: unknown code %q at %d", InvalidPackFormat, c, index)
+		}
+	}
+	if count >= 0 {
+		return nil, nil, fmt.Errorf("%w: repeat count without code", InvalidPackFormat)
+	}
+	return order, items, nil
+}
+
+// packSize returns the size in bytes of the code, the length prefix for strings and -1 for unknown codes
+func packSize(code byte) int {
+	switch code {
+	case 'b', 'B':
+		return 1
+	case 'h', 'H':
+		return 2
+	case 'i', 'I', 'f', 's':
+		return 4
+	case 'q', 'Q', 'd':
+		return 8
+	}
+	return -1
+}
+
+func packInteger(value *Value, code byte) (uint64, error) {
+	if value.TypeId() != IntId {
+		return 0, fmt.Errorf("%w: expecting Int for %q", PackOutOfRange, code)
+	}
+	bits := uint(packSize(code) * 8)
+	i := value.GetBigInt()
+	var low, high *big.Int
+	if code >= 'a' {
+		low = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), bits-1))
+		high = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits-1), big.NewInt(1))
+	} else {
+		low = big.NewInt(0)
+		high = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+	}
+	if i.Cmp(low) < 0 || i.Cmp(high) > 0 {
+		return 0, fmt.Errorf("%w: %s does not fit in %q", PackOutOfRange, i.String(), code)
+	}
+	if i.Sign() < 0 {
+		return uint64(i.Int64()), nil
+	}
+	return i.Uint64(), nil
+}
+
+func (plasma *Plasma) pack(format string, values []*Value) ([]byte, error) {
+	order, items, formatError := parsePackFormat(format)
+	if formatError != nil {
+		return nil, formatError
+	}
+	var result []byte
+	for _, item := range items {
+		for repeat := 0; repeat < item.count; repeat++ {
+			if len(values) == 0 {
+				return nil, fmt.Errorf("%w: not enough values", InvalidPackFormat)
+			}
+			value := values[0]
+			values = values[1:]
+			buffer := make([]byte, packSize(item.code))
+			switch item.code {
+			case 'f':
+				order.PutUint32(buffer, math.Float32bits(float32(value.Float())))
+			case 'd':
+				order.PutUint64(buffer, math.Float64bits(value.Float()))
+			case 's':
+				if value.TypeId() != StringId && value.TypeId() != BytesId {
+					return nil, fmt.Errorf("%w: expecting String or Bytes for 's'", PackOutOfRange)
+				}
+				contents := value.GetBytes()
+				if uint64(len(contents)) > math.MaxUint32 {
+					return nil, fmt.Errorf("%w: string too long", PackOutOfRange)
+				}
+				order.PutUint32(buffer, uint32(len(contents)))
+				buffer = append(buffer, contents...)
+			default:
+				u, packError := packInteger(value, item.code)
+				if packError != nil {
+					return nil, packError
+				}
+				switch len(buffer) {
+				case 1:
+					buffer[0] = byte(u)
+				case 2:
+					order.PutUint16(buffer, uint16(u))
+				case 4:
+					order.PutUint32(buffer, uint32(u))
+				case 8:
+					order.PutUint64(buffer, u)
+				}
+			}
+			result = append(result, buffer...)
+		}
+	}
+	if len(values) > 0 {
+		return nil, fmt.Errorf("%w: %d values left", InvalidPackFormat, len(values))
+	}
+	return result, nil
+}
+
+// unpack decodes the data with the format, the data must be consumed completely
+func (plasma *Plasma) unpack(format string, data []byte) ([]*Value, error) {
+	order, items, formatError := parsePackFormat(format)
+	if formatError != nil {
+		return nil, formatError
+	}
+	var result []*Value
+	for _, item := range items {
+		for repeat := 0; repeat < item.count; repeat++ {
+			size := packSize(item.code)
+			if len(data) < size {
+				return nil, fmt.Errorf("%w: %q needs %d bytes, %d left", NotEnoughData, item.code, size, len(data))
+			}
+			chunk := data[:size]
+			data = data[size:]
+			var value *Value
+			switch item.code {
+			case 'b':
+				value = plasma.NewInt(int64(int8(chunk[0])))
+			case 'B':
+				value = plasma.NewInt(int64(chunk[0]))
+			case 'h':
+				value = plasma.NewInt(int64(int16(order.Uint16(chunk))))
+			case 'H':
+				value = plasma.NewInt(int64(order.Uint16(chunk)))
+			case 'i':
+				value = plasma.NewInt(int64(int32(order.Uint32(chunk))))
+			case 'I':
+				value = plasma.NewInt(int64(order.Uint32(chunk)))
+			case 'q':
+				value = plasma.NewInt(int64(order.Uint64(chunk)))
+			case 'Q':
+				value = plasma.NewBigInt(new(big.Int).SetUint64(order.Uint64(chunk)))
+			case 'f':
+				value = plasma.NewFloat(float64(math.Float32frombits(order.Uint32(chunk))))
+			case 'd':
+				value = plasma.NewFloat(math.Float64frombits(order.Uint64(chunk)))
+			case 's':
+				length := uint64(order.Uint32(chunk))
+				if uint64(len(data)) < length {
+					return nil, fmt.Errorf("%w: string needs %d bytes, %d left", NotEnoughData, length, len(data))
+				}
+				contents := make([]byte, length)
+				copy(contents, data[:length])
+				data = data[length:]
+				if utf8Error := validUTF8(contents); utf8Error != nil {
+					return nil, utf8Error
+				}
+				value = plasma.NewString(contents)
+			}
+			result = append(result, value)
+		}
+	}
+	if len(data) > 0 {
+		return nil, fmt.Errorf("%w: %d bytes left", InvalidPackFormat, len(data))
+	}
+	return result, nil
+}
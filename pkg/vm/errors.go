@@ -6,4 +6,7 @@ var (
 	NotOperable   = fmt.Errorf("not operable")
 	NotIndexable  = fmt.Errorf("not indexable")
 	NotComparable = fmt.Errorf("not comparable")
+	EmptyIterable = fmt.Errorf("empty iterable")
+	InvalidRange  = fmt.Errorf("invalid range")
+	FrozenValue   = fmt.Errorf("built-in values can not be modified")
 )
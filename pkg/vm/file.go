@@ -0,0 +1,320 @@
+package vm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+var InvalidFileMode = fmt.Errorf("invalid file mode")
+
+// parseFileMode translates r, w, a and x, optionally followed by + and b, to os.O_* flags
+func parseFileMode(mode string) (flag int, binary bool, err error) {
+	if mode == "" {
+		return 0, false, fmt.Errorf("%w: %q", InvalidFileMode, mode)
+	}
+	switch mode[0] {
+	case 'r':
+		flag = os.O_RDONLY
+	case 'w':
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	case 'a':
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	case 'x':
+		flag = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	default:
+		return 0, false, fmt.Errorf("%w: %q", InvalidFileMode, mode)
+	}
+	for _, modifier := range mode[1:] {
+		switch {
+		case modifier == '+' && flag&os.O_RDWR == 0:
+			flag = flag&^os.O_WRONLY | os.O_RDWR
+		case modifier == 'b' && !binary:
+			binary = true
+		default:
+			return 0, false, fmt.Errorf("%w: %q", InvalidFileMode, mode)
+		}
+	}
+	return flag, binary, nil
+}
+
+/*
+fileHandle buffers the reads of an open file.
+Writes and seeks drop the read buffer, moving the file back to the position seen by the script.
+*/
+type fileHandle struct {
+	mutex  sync.Mutex
+	file   File
+	reader *bufio.Reader
+	closed bool
+}
+
+func (handle *fileHandle) check() error {
+	if handle.closed {
+		return fs.ErrClosed
+	}
+	return nil
+}
+
+func (handle *fileHandle) dropBuffer() error {
+	if buffered := handle.reader.Buffered(); buffered > 0 {
+		if _, seekError := handle.file.Seek(-int64(buffered), io.SeekCurrent); seekError != nil {
+			return seekError
+		}
+	}
+	handle.reader.Reset(handle.file)
+	return nil
+}
+
+// read returns up to n bytes, everything left when n is negative
+func (handle *fileHandle) read(n int64) ([]byte, error) {
+	if n < 0 {
+		return io.ReadAll(handle.reader)
+	}
+	contents, readError := io.ReadAll(io.LimitReader(handle.reader, n))
+	return contents, readError
+}
+
+// readLine returns the next line without its line terminator, ok is false at the end of the input
+func readLine(reader *bufio.Reader) (line []byte, ok bool, err error) {
+	line, err = reader.ReadBytes('\n')
+	if err == io.EOF {
+		if len(line) == 0 {
+			return nil, false, nil
+		}
+		err = nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	return bytes.TrimSuffix(line, []byte{'\r'}), true, nil
+}
+
+func (handle *fileHandle) write(contents []byte) (int, error) {
+	if dropError := handle.dropBuffer(); dropError != nil {
+		return 0, dropError
+	}
+	return handle.file.Write(contents)
+}
+
+func (handle *fileHandle) seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekCurrent {
+		offset -= int64(handle.reader.Buffered())
+	}
+	position, seekError := handle.file.Seek(offset, whence)
+	if seekError != nil {
+		return 0, seekError
+	}
+	handle.reader.Reset(handle.file)
+	return position, nil
+}
+
+func (handle *fileHandle) close() error {
+	if handle.closed {
+		return nil
+	}
+	handle.closed = true
+	return handle.file.Close()
+}
+
+/*
+newFile methods:
+Read		read
+ReadLine	read_line
+Write		write
+Seek		seek
+Tell		tell
+Close		close
+HasNext		__has_next__
+Next		__next__
+Iterating a file yields its lines, text files return String and binary ones Bytes.
+Closing twice is allowed so close can be deferred right after open.
+*/
+func (plasma *Plasma) newFile(file File, binary bool) *Value {
+	handle := &fileHandle{file: file, reader: bufio.NewReader(file)}
+	wrap := plasma.NewString
+	if binary {
+		wrap = plasma.NewBytes
+	}
+	result := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	result.SetAny(handle)
+	method := func(name string, parameters []Parameter, callback func(ctx *context, argument ...*Value) (*Value, error)) {
+		plasma.define(result.vtable, name, parameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			handle.mutex.Lock()
+			defer handle.mutex.Unlock()
+			if checkError := handle.check(); checkError != nil && name != magic_functions.Close {
+				return nil, checkError
+			}
+			return callback(ctx, argument...)
+		})
+	}
+	method(magic_functions.Read, []Parameter{optional("n", IntId, NoneId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n := int64(-1)
+		if len(argument) > 0 && argument[0].TypeId() != NoneId {
+			var intError error
+			n, intError = argument[0].Int64()
+			if intError != nil {
+				return nil, intError
+			}
+		}
+		contents, readError := handle.read(n)
+		if readError != nil {
+			return nil, readError
+		}
+		return wrap(contents), nil
+	})
+	method(magic_functions.ReadLine, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		line, ok, readError := readLine(handle.reader)
+		if readError != nil {
+			return nil, readError
+		}
+		if !ok {
+			return plasma.none, nil
+		}
+		return wrap(line), nil
+	})
+	method(magic_functions.Write, []Parameter{param("contents")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case StringId, BytesId:
+			written, writeError := handle.write(argument[0].GetBytes())
+			if writeError != nil {
+				return nil, writeError
+			}
+			return plasma.NewInt(int64(written)), nil
+		}
+		return nil, NotOperable
+	})
+	method(magic_functions.Seek, []Parameter{param("offset", IntId), optional("whence", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		whence := io.SeekStart
+		if len(argument) > 1 {
+			whence = int(argument[1].Int())
+		}
+		offset, intError := argument[0].Int64()
+		if intError != nil {
+			return nil, intError
+		}
+		position, seekError := handle.seek(offset, whence)
+		if seekError != nil {
+			return nil, seekError
+		}
+		return plasma.NewInt(position), nil
+	})
+	method(magic_functions.Tell, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		position, seekError := handle.seek(0, io.SeekCurrent)
+		if seekError != nil {
+			return nil, seekError
+		}
+		return plasma.NewInt(position), nil
+	})
+	method(magic_functions.Close, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, handle.close()
+	})
+	method(magic_functions.HasNext, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		_, peekError := handle.reader.Peek(1)
+		if peekError == io.EOF {
+			return plasma.false, nil
+		}
+		if peekError != nil {
+			return nil, peekError
+		}
+		return plasma.true, nil
+	})
+	method(magic_functions.Next, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		line, _, readError := readLine(handle.reader)
+		if readError != nil {
+			return nil, readError
+		}
+		return wrap(line), nil
+	})
+	return result
+}
+
+func (plasma *Plasma) openFile(name, mode string) (*Value, error) {
+	fileSystem, disabledError := plasma.FileSystem()
+	if disabledError != nil {
+		return nil, disabledError
+	}
+	flag, binary, modeError := parseFileMode(mode)
+	if modeError != nil {
+		return nil, modeError
+	}
+	file, openError := fileSystem.OpenFile(name, flag, 0644)
+	if openError != nil {
+		return nil, openError
+	}
+	return plasma.newFile(file, binary), nil
+}
+
+func (plasma *Plasma) readFile(name string) (*Value, error) {
+	fileSystem, disabledError := plasma.FileSystem()
+	if disabledError != nil {
+		return nil, disabledError
+	}
+	file, openError := fileSystem.OpenFile(name, os.O_RDONLY, 0)
+	if openError != nil {
+		return nil, openError
+	}
+	defer file.Close()
+	contents, readError := io.ReadAll(file)
+	if readError != nil {
+		return nil, readError
+	}
+	return plasma.NewString(contents), nil
+}
+
+func (plasma *Plasma) writeFile(name string, contents []byte) error {
+	fileSystem, disabledError := plasma.FileSystem()
+	if disabledError != nil {
+		return disabledError
+	}
+	file, openError := fileSystem.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if openError != nil {
+		return openError
+	}
+	_, writeError := file.Write(contents)
+	closeError := file.Close()
+	if writeError != nil {
+		return writeError
+	}
+	return closeError
+}
+
+// listDir returns the names of the directory entries sorted by name
+func (plasma *Plasma) listDir(name string) (*Value, error) {
+	fileSystem, disabledError := plasma.FileSystem()
+	if disabledError != nil {
+		return nil, disabledError
+	}
+	entries, readError := fileSystem.ReadDir(name)
+	if readError != nil {
+		return nil, readError
+	}
+	names := make([]*Value, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, plasma.NewString([]byte(entry.Name())))
+	}
+	return plasma.NewArray(names), nil
+}
+
+func (plasma *Plasma) exists(name string) (*Value, error) {
+	fileSystem, disabledError := plasma.FileSystem()
+	if disabledError != nil {
+		return nil, disabledError
+	}
+	_, statError := fileSystem.Stat(name)
+	if errors.Is(statError, fs.ErrNotExist) {
+		return plasma.false, nil
+	}
+	if statError != nil {
+		return nil, statError
+	}
+	return plasma.true, nil
+}
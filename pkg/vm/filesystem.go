@@ -0,0 +1,424 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	FileSystemDisabled = fmt.Errorf("file system access is disabled")
+	FileTooLarge       = fmt.Errorf("file too large")
+)
+
+// DefaultMaxFileSize is the size limit of the files written in a new MemoryFS, see SetMaxFileSize
+const DefaultMaxFileSize int64 = 64 << 20
+
+type (
+	// File is an open file of a FileSystem, files that can not be written or seeked return an error
+	File interface {
+		io.Reader
+		io.Writer
+		io.Seeker
+		io.Closer
+	}
+	/*
+		FileSystem is the only way scripts can reach files. Names always use forward slashes and are
+		relative to the root of the file system, flag receives the os.O_* flags.
+		The VM has no file system until the host sets one with SetFileSystem.
+	*/
+	FileSystem interface {
+		OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+		ReadDir(name string) ([]fs.DirEntry, error)
+		Stat(name string) (fs.FileInfo, error)
+	}
+)
+
+func (plasma *Plasma) SetFileSystem(fileSystem FileSystem) {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	plasma.fileSystem = fileSystem
+}
+
+func (plasma *Plasma) FileSystem() (FileSystem, error) {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	if plasma.fileSystem == nil {
+		return nil, FileSystemDisabled
+	}
+	return plasma.fileSystem, nil
+}
+
+// cleanPath resolves the name against the root, so it can never escape it with ..
+func cleanPath(name string) string {
+	cleaned := path.Clean("/" + name)[1:]
+	if cleaned == "" {
+		return "."
+	}
+	return cleaned
+}
+
+// renamePathError hides the host path from errors
+func renamePathError(err error, name string) error {
+	var pathError *fs.PathError
+	if errors.As(err, &pathError) {
+		return &fs.PathError{Op: pathError.Op, Path: name, Err: pathError.Err}
+	}
+	return err
+}
+
+/*
+dirFS roots the file system in a directory of the host. Symbolic links are followed only while
+they stay inside the root, links pointing outside of it (or broken ones) fail with fs.ErrPermission.
+*/
+type dirFS struct {
+	root string
+}
+
+func DirFS(root string) FileSystem {
+	return &dirFS{root: root}
+}
+
+/*
+resolve returns the host path of the name with its symbolic links evaluated. Names that do not
+exist yet are resolved from their longest existing parent, so files can still be created.
+*/
+func (d *dirFS) resolve(op, name string) (string, error) {
+	root, rootError := filepath.EvalSymlinks(d.root)
+	if rootError != nil {
+		return "", renamePathError(rootError, name)
+	}
+	denied := &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+	existing, missing := filepath.Join(root, filepath.FromSlash(cleanPath(name))), ""
+	for {
+		resolved, evalError := filepath.EvalSymlinks(existing)
+		if evalError == nil {
+			existing = resolved
+			break
+		}
+		// Either a broken link, whose target could be created outside the root, or the root itself
+		if _, lstatError := os.Lstat(existing); lstatError == nil || existing == root {
+			return "", denied
+		}
+		missing = filepath.Join(filepath.Base(existing), missing)
+		existing = filepath.Dir(existing)
+	}
+	relative, relError := filepath.Rel(root, existing)
+	if relError != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", denied
+	}
+	return filepath.Join(existing, missing), nil
+}
+
+func (d *dirFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	resolved, resolveError := d.resolve("open", name)
+	if resolveError != nil {
+		return nil, resolveError
+	}
+	file, openError := os.OpenFile(resolved, flag, perm)
+	if openError != nil {
+		return nil, renamePathError(openError, name)
+	}
+	return file, nil
+}
+
+func (d *dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	resolved, resolveError := d.resolve("readdir", name)
+	if resolveError != nil {
+		return nil, resolveError
+	}
+	entries, readError := os.ReadDir(resolved)
+	return entries, renamePathError(readError, name)
+}
+
+func (d *dirFS) Stat(name string) (fs.FileInfo, error) {
+	resolved, resolveError := d.resolve("stat", name)
+	if resolveError != nil {
+		return nil, resolveError
+	}
+	info, statError := os.Stat(resolved)
+	return info, renamePathError(statError, name)
+}
+
+// readOnlyFS adapts an io/fs file system, like embed.FS or fstest.MapFS
+type readOnlyFS struct {
+	fsys fs.FS
+}
+
+func ReadOnlyFS(fsys fs.FS) FileSystem {
+	return &readOnlyFS{fsys: fsys}
+}
+
+type readOnlyFile struct {
+	fs.File
+	name string
+}
+
+func (file *readOnlyFile) Write(_ []byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: file.name, Err: fs.ErrPermission}
+}
+
+func (file *readOnlyFile) Seek(offset int64, whence int) (int64, error) {
+	if seeker, ok := file.File.(io.Seeker); ok {
+		return seeker.Seek(offset, whence)
+	}
+	return 0, &fs.PathError{Op: "seek", Path: file.name, Err: fs.ErrInvalid}
+}
+
+func (r *readOnlyFS) OpenFile(name string, flag int, _ fs.FileMode) (File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	file, openError := r.fsys.Open(cleanPath(name))
+	if openError != nil {
+		return nil, renamePathError(openError, name)
+	}
+	return &readOnlyFile{File: file, name: name}, nil
+}
+
+func (r *readOnlyFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, readError := fs.ReadDir(r.fsys, cleanPath(name))
+	return entries, renamePathError(readError, name)
+}
+
+func (r *readOnlyFS) Stat(name string) (fs.FileInfo, error) {
+	info, statError := fs.Stat(r.fsys, cleanPath(name))
+	return info, renamePathError(statError, name)
+}
+
+/*
+MemoryFS keeps the files in memory, directories are implicit: any prefix of a file path is one.
+It is meant for tests and sandboxes where scripts should not touch the disk.
+*/
+type MemoryFS struct {
+	mutex       sync.Mutex
+	files       map[string]*memoryData
+	maxFileSize int64
+}
+
+type memoryData struct {
+	mutex    sync.Mutex
+	contents []byte
+	modTime  time.Time
+}
+
+type memoryInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (info *memoryInfo) Name() string       { return info.name }
+func (info *memoryInfo) Size() int64        { return info.size }
+func (info *memoryInfo) ModTime() time.Time { return info.modTime }
+func (info *memoryInfo) IsDir() bool        { return info.dir }
+func (info *memoryInfo) Sys() any           { return nil }
+func (info *memoryInfo) Mode() fs.FileMode {
+	if info.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+type memoryFile struct {
+	name     string
+	data     *memoryData
+	flag     int
+	position int64
+	closed   bool
+	maxSize  int64
+}
+
+func NewMemoryFS() *MemoryFS {
+	return &MemoryFS{files: map[string]*memoryData{}, maxFileSize: DefaultMaxFileSize}
+}
+
+// SetMaxFileSize limits the size scripts can grow a file to, writes past it fail with FileTooLarge.
+// It applies to the files opened afterwards
+func (m *MemoryFS) SetMaxFileSize(size int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.maxFileSize = size
+}
+
+// WriteFile is a helper for hosts to populate the file system
+func (m *MemoryFS) WriteFile(name string, contents []byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.files[cleanPath(name)] = &memoryData{contents: append([]byte(nil), contents...), modTime: time.Now()}
+}
+
+func (m *MemoryFS) isDir(name string) bool {
+	if name == "." {
+		return true
+	}
+	for fileName := range m.files {
+		if strings.HasPrefix(fileName, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryFS) OpenFile(name string, flag int, _ fs.FileMode) (File, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	cleaned := cleanPath(name)
+	data, found := m.files[cleaned]
+	switch {
+	case m.isDir(cleaned):
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	case !found && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case found && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case !found:
+		data = &memoryData{modTime: time.Now()}
+		m.files[cleaned] = data
+	}
+	if flag&os.O_TRUNC != 0 && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		data.mutex.Lock()
+		data.contents = nil
+		data.modTime = time.Now()
+		data.mutex.Unlock()
+	}
+	return &memoryFile{name: name, data: data, flag: flag, maxSize: m.maxFileSize}, nil
+}
+
+func (m *MemoryFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	cleaned := cleanPath(name)
+	if !m.isDir(cleaned) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	prefix := cleaned + "/"
+	if cleaned == "." {
+		prefix = ""
+	}
+	infos := map[string]*memoryInfo{}
+	for fileName, data := range m.files {
+		if !strings.HasPrefix(fileName, prefix) {
+			continue
+		}
+		entry := fileName[len(prefix):]
+		if slash := strings.IndexByte(entry, '/'); slash >= 0 {
+			infos[entry[:slash]] = &memoryInfo{name: entry[:slash], dir: true}
+			continue
+		}
+		data.mutex.Lock()
+		infos[entry] = &memoryInfo{name: entry, size: int64(len(data.contents)), modTime: data.modTime}
+		data.mutex.Unlock()
+	}
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (m *MemoryFS) Stat(name string) (fs.FileInfo, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	cleaned := cleanPath(name)
+	if m.isDir(cleaned) {
+		return &memoryInfo{name: path.Base(cleaned), dir: true}, nil
+	}
+	data, found := m.files[cleaned]
+	if !found {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+	return &memoryInfo{name: path.Base(cleaned), size: int64(len(data.contents)), modTime: data.modTime}, nil
+}
+
+func (file *memoryFile) check(op string, allowed bool) error {
+	switch {
+	case file.closed:
+		return &fs.PathError{Op: op, Path: file.name, Err: fs.ErrClosed}
+	case !allowed:
+		return &fs.PathError{Op: op, Path: file.name, Err: fs.ErrPermission}
+	}
+	return nil
+}
+
+func (file *memoryFile) Read(b []byte) (int, error) {
+	if checkError := file.check("read", file.flag&os.O_WRONLY == 0); checkError != nil {
+		return 0, checkError
+	}
+	file.data.mutex.Lock()
+	defer file.data.mutex.Unlock()
+	if file.position >= int64(len(file.data.contents)) {
+		return 0, io.EOF
+	}
+	n := copy(b, file.data.contents[file.position:])
+	file.position += int64(n)
+	return n, nil
+}
+
+func (file *memoryFile) Write(b []byte) (int, error) {
+	if checkError := file.check("write", file.flag&(os.O_WRONLY|os.O_RDWR) != 0); checkError != nil {
+		return 0, checkError
+	}
+	file.data.mutex.Lock()
+	defer file.data.mutex.Unlock()
+	if file.flag&os.O_APPEND != 0 {
+		file.position = int64(len(file.data.contents))
+	}
+	if int64(len(b)) > file.maxSize-file.position {
+		return 0, &fs.PathError{Op: "write", Path: file.name, Err: FileTooLarge}
+	}
+	if gap := file.position - int64(len(file.data.contents)); gap > 0 {
+		file.data.contents = append(file.data.contents, bytes.Repeat([]byte{0}, int(gap))...)
+	}
+	end := file.position + int64(len(b))
+	if end > int64(len(file.data.contents)) {
+		file.data.contents = append(file.data.contents[:file.position], b...)
+	} else {
+		copy(file.data.contents[file.position:], b)
+	}
+	file.position = end
+	file.data.modTime = time.Now()
+	return len(b), nil
+}
+
+func (file *memoryFile) Seek(offset int64, whence int) (int64, error) {
+	if checkError := file.check("seek", true); checkError != nil {
+		return 0, checkError
+	}
+	file.data.mutex.Lock()
+	defer file.data.mutex.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += file.position
+	case io.SeekEnd:
+		offset += int64(len(file.data.contents))
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: file.name, Err: fs.ErrInvalid}
+	}
+	file.position = offset
+	return offset, nil
+}
+
+func (file *memoryFile) Close() error {
+	if checkError := file.check("close", true); checkError != nil {
+		return checkError
+	}
+	file.closed = true
+	return nil
+}
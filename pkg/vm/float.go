@@ -3,12 +3,13 @@ package vm
 import (
 	"encoding/binary"
 	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
 	"math"
 )
 
 func (plasma *Plasma) floatClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.Float, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewFloat(argument[0].Float()), nil
 	}))
 	return class
@@ -50,281 +51,191 @@ FromLittle			from_little
 func (plasma *Plasma) NewFloat(f float64) *Value {
 	result := plasma.NewValue(plasma.rootSymbols, FloatId, plasma.float)
 	result.SetAny(f)
-	result.Set(magic_functions.Positive, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result, nil
-		},
-	))
-	result.Set(magic_functions.Negative, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewFloat(-result.Float()), nil
-		},
-	))
-	result.Set(magic_functions.NegateBits, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewFloat(math.Float64frombits(^math.Float64bits(result.Float()))), nil
-		},
-	))
-	result.Set(magic_functions.Equal, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewBool(result.Equal(argument[0])), nil
-			}
-			return plasma.false, nil
-		},
-	))
-	result.Set(magic_functions.NotEqual, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewBool(!result.Equal(argument[0])), nil
-			}
-			return plasma.true, nil
-		},
-	))
-	result.Set(magic_functions.GreaterThan, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewBool(result.Float() > argument[0].Float()), nil
-			}
-			return nil, NotComparable
-		},
-	))
-	result.Set(magic_functions.GreaterOrEqualThan, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewBool(result.Float() >= argument[0].Float()), nil
-			}
-			return nil, NotComparable
-		},
-	))
-	result.Set(magic_functions.LessThan, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewBool(result.Float() < argument[0].Float()), nil
-			}
-			return nil, NotComparable
-		},
-	))
-	result.Set(magic_functions.LessOrEqualThan, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewBool(result.Float() <= argument[0].Float()), nil
-			}
-			return nil, NotComparable
-		},
-	))
-	result.Set(magic_functions.BitwiseOr, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(
-					math.Float64frombits(
-						math.Float64bits(result.Float()) | math.Float64bits(argument[0].Float()),
-					),
-				), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.BitwiseXor, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(
-					math.Float64frombits(
-						math.Float64bits(result.Float()) ^ math.Float64bits(argument[0].Float()),
-					),
-				), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.BitwiseAnd, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(
-					math.Float64frombits(
-						math.Float64bits(result.Float()) & math.Float64bits(argument[0].Float()),
-					),
-				), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.BitwiseLeft, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(
-					math.Float64frombits(
-						math.Float64bits(result.Float()) << math.Float64bits(argument[0].Float()),
-					),
-				), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.BitwiseRight, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(
-					math.Float64frombits(
-						math.Float64bits(result.Float()) >> math.Float64bits(argument[0].Float()),
-					),
-				), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Add, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(result.Float() + argument[0].Float()), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Sub, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(result.Float() - argument[0].Float()), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Mul, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(result.Float() * argument[0].Float()), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Div, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(result.Float() / argument[0].Float()), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.FloorDiv, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewInt(int64(result.Float() / argument[0].Float())), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Modulus, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(math.Mod(result.Float(), argument[0].Float())), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.PowerOf, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId, FloatId:
-				return plasma.NewFloat(math.Pow(result.Float(), argument[0].Float())), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Bool, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(result.Bool()), nil
-		},
-	))
-	result.Set(magic_functions.String, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewString([]byte(result.String())), nil
-		},
-	))
-	result.Set(magic_functions.Int, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewInt(result.Int()), nil
-		},
-	))
-	result.Set(magic_functions.Float, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result, nil
-		},
-	))
-	result.Set(magic_functions.Copy, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewFloat(result.Float()), nil
-		},
-	))
-	result.Set(magic_functions.BigEndian, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			b := make([]byte, 8)
-			binary.BigEndian.PutUint64(b, math.Float64bits(result.Float()))
-			return plasma.NewBytes(b), nil
-		},
-	))
-	result.Set(magic_functions.LittleEndian, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			b := make([]byte, 8)
-			binary.LittleEndian.PutUint64(b, math.Float64bits(result.Float()))
-			return plasma.NewBytes(b), nil
-		},
-	))
-	result.Set(magic_functions.FromBig, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewFloat(math.Float64frombits(binary.BigEndian.Uint64(argument[0].GetBytes()))), nil
-		},
-	))
-	result.Set(magic_functions.FromLittle, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewFloat(math.Float64frombits(binary.LittleEndian.Uint64(argument[0].GetBytes()))), nil
-		},
-	))
+	plasma.define(result.vtable, magic_functions.Positive, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Negative, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(-result.Float()), nil
+	})
+	plasma.define(result.vtable, magic_functions.NegateBits, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(math.Float64frombits(^math.Float64bits(result.Float()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(result.Equal(argument[0])), nil
+		}
+		return plasma.false, nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(!result.Equal(argument[0])), nil
+		}
+		return plasma.true, nil
+	})
+	plasma.define(result.vtable, magic_functions.GreaterThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(result.Float() > argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.GreaterOrEqualThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(result.Float() >= argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.LessThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(result.Float() < argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.LessOrEqualThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(result.Float() <= argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseOr, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(
+				math.Float64frombits(
+					math.Float64bits(result.Float()) | math.Float64bits(argument[0].Float()),
+				),
+			), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseXor, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(
+				math.Float64frombits(
+					math.Float64bits(result.Float()) ^ math.Float64bits(argument[0].Float()),
+				),
+			), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseAnd, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(
+				math.Float64frombits(
+					math.Float64bits(result.Float()) & math.Float64bits(argument[0].Float()),
+				),
+			), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseLeft, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(
+				math.Float64frombits(
+					math.Float64bits(result.Float()) << math.Float64bits(argument[0].Float()),
+				),
+			), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseRight, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(
+				math.Float64frombits(
+					math.Float64bits(result.Float()) >> math.Float64bits(argument[0].Float()),
+				),
+			), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Add, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(result.Float() + argument[0].Float()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Sub, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(result.Float() - argument[0].Float()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Mul, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(result.Float() * argument[0].Float()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Div, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(result.Float() / argument[0].Float()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.FloorDiv, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewInt(int64(result.Float() / argument[0].Float())), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Modulus, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(math.Mod(result.Float(), argument[0].Float())), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.PowerOf, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(math.Pow(result.Float(), argument[0].Float())), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result.Bool()), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(result.String())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Int, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(result.Int()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Float, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Copy, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(result.Float()), nil
+	})
+	plasma.define(result.vtable, magic_functions.BigEndian, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(result.Float()))
+		return plasma.NewBytes(b), nil
+	})
+	plasma.define(result.vtable, magic_functions.LittleEndian, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, math.Float64bits(result.Float()))
+		return plasma.NewBytes(b), nil
+	})
+	plasma.define(result.vtable, magic_functions.FromBig, []Parameter{param("contents", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(math.Float64frombits(binary.BigEndian.Uint64(argument[0].GetBytes()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.FromLittle, []Parameter{param("contents", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(math.Float64frombits(binary.LittleEndian.Uint64(argument[0].GetBytes()))), nil
+	})
 	return result
 }
@@ -1,8 +1,10 @@
 package vm
 
+import special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+
 func (plasma *Plasma) functionClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.Function, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewBuiltInFunction(
 			plasma.rootSymbols,
 			func(argument ...*Value) (*Value, error) {
@@ -0,0 +1,298 @@
+package vm
+
+import (
+	"sync"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+// pullFunc returns the next value of a sequence, ok is false once the sequence is exhausted
+type pullFunc func(ctx *context) (value *Value, ok bool, err error)
+
+// puller adapts any iterable to a pullFunc, arrays and tuples are indexed directly
+func (plasma *Plasma) puller(ctx *context, iterable *Value) (pullFunc, error) {
+	switch iterable.TypeId() {
+	case ArrayId, TupleId:
+		index := 0
+		return func(*context) (*Value, bool, error) {
+			values := iterable.GetValues()
+			if index >= len(values) {
+				return nil, false, nil
+			}
+			index++
+			return values[index-1], true, nil
+		}, nil
+	}
+	iter, iterError := plasma.callMethod(ctx, iterable, magic_functions.Iter)
+	if iterError != nil {
+		return nil, iterError
+	}
+	return func(ctx *context) (*Value, bool, error) {
+		hasNext, hasNextError := plasma.callMethod(ctx, iter, magic_functions.HasNext)
+		if hasNextError != nil {
+			return nil, false, hasNextError
+		}
+		if !hasNext.Bool() {
+			return nil, false, nil
+		}
+		value, nextError := plasma.callMethod(ctx, iter, magic_functions.Next)
+		if nextError != nil {
+			return nil, false, nextError
+		}
+		return value, true, nil
+	}, nil
+}
+
+func (plasma *Plasma) pullers(ctx *context, iterables []*Value) ([]pullFunc, error) {
+	result := make([]pullFunc, 0, len(iterables))
+	for _, iterable := range iterables {
+		pull, pullError := plasma.puller(ctx, iterable)
+		if pullError != nil {
+			return nil, pullError
+		}
+		result = append(result, pull)
+	}
+	return result, nil
+}
+
+/*
+newLazyIterator exposes a pullFunc through the iterator protocol.
+__has_next__ pulls one value ahead and keeps it until __next__ consumes it,
+so pull is never called more than once per produced value.
+*/
+func (plasma *Plasma) newLazyIterator(pull pullFunc) *Value {
+	var (
+		mutex     sync.Mutex
+		buffered  *Value
+		hasBuffer bool
+		exhausted bool
+	)
+	fill := func(ctx *context) error {
+		if hasBuffer || exhausted {
+			return nil
+		}
+		value, ok, pullError := pull(ctx)
+		if pullError != nil {
+			return pullError
+		}
+		if !ok {
+			exhausted = true
+			return nil
+		}
+		buffered, hasBuffer = value, true
+		return nil
+	}
+	iter := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if fillError := fill(ctx); fillError != nil {
+			return nil, fillError
+		}
+		return plasma.NewBool(hasBuffer), nil
+	})
+	plasma.define(iter.vtable, magic_functions.Next, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if fillError := fill(ctx); fillError != nil {
+			return nil, fillError
+		}
+		if !hasBuffer {
+			return plasma.none, nil
+		}
+		value := buffered
+		buffered, hasBuffer = nil, false
+		return value, nil
+	})
+	return iter
+}
+
+func (plasma *Plasma) lazyMap(ctx *context, function *Value, iterables []*Value) (*Value, error) {
+	sources, pullError := plasma.pullers(ctx, iterables)
+	if pullError != nil {
+		return nil, pullError
+	}
+	return plasma.newLazyIterator(func(ctx *context) (*Value, bool, error) {
+		arguments := make([]*Value, 0, len(sources))
+		for _, source := range sources {
+			value, ok, sourceError := source(ctx)
+			if sourceError != nil || !ok {
+				return nil, false, sourceError
+			}
+			arguments = append(arguments, value)
+		}
+		result, callError := plasma.call(ctx, function, arguments...)
+		if callError != nil {
+			return nil, false, callError
+		}
+		return result, true, nil
+	}), nil
+}
+
+// lazyFilter keeps the values the predicate accepts, a none predicate keeps the truthy values
+func (plasma *Plasma) lazyFilter(ctx *context, predicate, iterable *Value) (*Value, error) {
+	source, pullError := plasma.puller(ctx, iterable)
+	if pullError != nil {
+		return nil, pullError
+	}
+	return plasma.newLazyIterator(func(ctx *context) (*Value, bool, error) {
+		for {
+			value, ok, sourceError := source(ctx)
+			if sourceError != nil || !ok {
+				return nil, false, sourceError
+			}
+			test := value
+			if predicate.TypeId() != NoneId {
+				var callError error
+				test, callError = plasma.call(ctx, predicate, value)
+				if callError != nil {
+					return nil, false, callError
+				}
+			}
+			if test.Bool() {
+				return value, true, nil
+			}
+		}
+	}), nil
+}
+
+// lazyZip produces tuples until the shortest iterable is exhausted
+func (plasma *Plasma) lazyZip(ctx *context, iterables []*Value) (*Value, error) {
+	sources, pullError := plasma.pullers(ctx, iterables)
+	if pullError != nil {
+		return nil, pullError
+	}
+	return plasma.newLazyIterator(func(ctx *context) (*Value, bool, error) {
+		if len(sources) == 0 {
+			return nil, false, nil
+		}
+		values := make([]*Value, 0, len(sources))
+		for _, source := range sources {
+			value, ok, sourceError := source(ctx)
+			if sourceError != nil || !ok {
+				return nil, false, sourceError
+			}
+			values = append(values, value)
+		}
+		return plasma.NewTuple(values), true, nil
+	}), nil
+}
+
+func (plasma *Plasma) lazyEnumerate(ctx *context, iterable *Value, start int64) (*Value, error) {
+	source, pullError := plasma.puller(ctx, iterable)
+	if pullError != nil {
+		return nil, pullError
+	}
+	index := start
+	return plasma.newLazyIterator(func(ctx *context) (*Value, bool, error) {
+		value, ok, sourceError := source(ctx)
+		if sourceError != nil || !ok {
+			return nil, false, sourceError
+		}
+		index++
+		return plasma.NewTuple([]*Value{plasma.NewInt(index - 1), value}), true, nil
+	}), nil
+}
+
+func (plasma *Plasma) lazyTake(ctx *context, iterable *Value, n int64) (*Value, error) {
+	source, pullError := plasma.puller(ctx, iterable)
+	if pullError != nil {
+		return nil, pullError
+	}
+	var taken int64
+	return plasma.newLazyIterator(func(ctx *context) (*Value, bool, error) {
+		if taken >= n {
+			return nil, false, nil
+		}
+		taken++
+		return source(ctx)
+	}), nil
+}
+
+func (plasma *Plasma) lazySkip(ctx *context, iterable *Value, n int64) (*Value, error) {
+	source, pullError := plasma.puller(ctx, iterable)
+	if pullError != nil {
+		return nil, pullError
+	}
+	skipped := false
+	return plasma.newLazyIterator(func(ctx *context) (*Value, bool, error) {
+		if !skipped {
+			skipped = true
+			for i := int64(0); i < n; i++ {
+				_, ok, sourceError := source(ctx)
+				if sourceError != nil || !ok {
+					return nil, false, sourceError
+				}
+			}
+		}
+		return source(ctx)
+	}), nil
+}
+
+// lazyChain iterates the iterables one after the other, each one is only opened when reached
+func (plasma *Plasma) lazyChain(iterables []*Value) *Value {
+	var current pullFunc
+	return plasma.newLazyIterator(func(ctx *context) (*Value, bool, error) {
+		for {
+			if current == nil {
+				if len(iterables) == 0 {
+					return nil, false, nil
+				}
+				var pullError error
+				current, pullError = plasma.puller(ctx, iterables[0])
+				if pullError != nil {
+					return nil, false, pullError
+				}
+				iterables = iterables[1:]
+			}
+			value, ok, sourceError := current(ctx)
+			if sourceError != nil {
+				return nil, false, sourceError
+			}
+			if ok {
+				return value, true, nil
+			}
+			current = nil
+		}
+	})
+}
+
+func (plasma *Plasma) reduce(ctx *context, function, iterable *Value, initial *Value) (*Value, error) {
+	accumulator := initial
+	iterError := plasma.iterate(ctx, iterable, func(value *Value) error {
+		if accumulator == nil {
+			accumulator = value
+			return nil
+		}
+		var callError error
+		accumulator, callError = plasma.call(ctx, function, accumulator, value)
+		return callError
+	})
+	if iterError != nil {
+		return nil, iterError
+	}
+	if accumulator == nil {
+		return nil, EmptyIterable
+	}
+	return accumulator, nil
+}
+
+// anyOrAll stops at the first value whose truthiness differs from the expected one
+func (plasma *Plasma) anyOrAll(ctx *context, iterable *Value, expected bool) (*Value, error) {
+	source, pullError := plasma.puller(ctx, iterable)
+	if pullError != nil {
+		return nil, pullError
+	}
+	for {
+		value, ok, sourceError := source(ctx)
+		if sourceError != nil {
+			return nil, sourceError
+		}
+		if !ok {
+			return plasma.NewBool(expected), nil
+		}
+		if value.Bool() != expected {
+			return plasma.NewBool(!expected), nil
+		}
+	}
+}
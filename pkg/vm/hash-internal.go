@@ -1,84 +1,303 @@
 package vm
 
 import (
+	"bytes"
 	"fmt"
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	"math"
+	"math/big"
 	"reflect"
 	"sync"
 )
 
 var (
 	NotHashable = fmt.Errorf("not hashable")
+	KeyNotFound = fmt.Errorf("key not found")
 )
 
-func createHashString(a any) string {
-	return fmt.Sprintf("%s -- %v", reflect.TypeOf(a), a)
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+func fnvHash(seed TypeId, b []byte) uint64 {
+	h := uint64(fnvOffset64) ^ uint64(seed)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return h
+}
+
+type (
+	KeyValue struct {
+		Key, Value *Value
+	}
+	hashEntry struct {
+		key, value     *Value
+		hash           uint64
+		removed        bool
+		previous, next *hashEntry
+	}
+	/*
+		Hash stores the original key and value pairs preserving insertion order.
+		Keys are grouped in buckets by their hash, collisions are resolved with keysEqual
+	*/
+	Hash struct {
+		plasma      *Plasma
+		mutex       *sync.Mutex
+		buckets     map[uint64][]*hashEntry
+		first, last *hashEntry
+		size        int64
+	}
+)
+
+func (h *Hash) hashOf(ctx *context, key *Value) (uint64, error) {
+	switch key.TypeId() {
+	case StringId, BytesId:
+		return fnvHash(key.TypeId(), key.GetBytes()), nil
+	case BoolId:
+		if key.GetBool() {
+			return fnvHash(BoolId, []byte{1}), nil
+		}
+		return fnvHash(BoolId, []byte{0}), nil
+	case NoneId:
+		return fnvHash(NoneId, nil), nil
+	case IntId:
+		if key.isBigInt() {
+			i := key.GetBigInt()
+			// Big integers exactly representable as floats share the hash of that float
+			if f, accuracy := new(big.Float).SetInt(i).Float64(); accuracy == big.Exact {
+				return math.Float64bits(f), nil
+			}
+			return fnvHash(IntId, i.Bytes()) ^ uint64(i.Sign()), nil
+		}
+		return uint64(key.GetInt64()), nil
+	case FloatId:
+		f := key.GetFloat64()
+		// Integral floats share the hash of their Int counterpart since they compare equal
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return uint64(int64(f)), nil
+		}
+		return math.Float64bits(f), nil
+	case TupleId:
+		result := fnvHash(TupleId, nil)
+		for _, value := range key.GetValues() {
+			valueHash, hashError := h.hashOf(ctx, value)
+			if hashError != nil {
+				return 0, hashError
+			}
+			result = (result ^ valueHash) * fnvPrime64
+		}
+		return result, nil
+	case ArrayId, HashId, SetId:
+		return 0, NotHashable
+	case ValueId:
+		hashFunc, getError := key.Get(magic_functions.Hash)
+		if getError == nil {
+			result, callError := h.plasma.call(ctx, hashFunc)
+			if callError != nil {
+				return 0, callError
+			}
+			return uint64(result.Int()), nil
+		}
+	}
+	return uint64(reflect.ValueOf(key).Pointer()), nil
 }
 
-type Hash struct {
-	mutex       *sync.Mutex
-	internalMap map[string]*Value
+func (h *Hash) keysEqual(ctx *context, a, b *Value) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+	aType, bType := a.TypeId(), b.TypeId()
+	switch aType {
+	case IntId, FloatId:
+		switch bType {
+		case IntId, FloatId:
+			return numericEqual(a, b), nil
+		}
+		return false, nil
+	case StringId, BytesId:
+		return aType == bType && bytes.Equal(a.GetBytes(), b.GetBytes()), nil
+	case BoolId:
+		return bType == BoolId && a.GetBool() == b.GetBool(), nil
+	case TupleId:
+		if bType != TupleId {
+			return false, nil
+		}
+		aValues, bValues := a.GetValues(), b.GetValues()
+		if len(aValues) != len(bValues) {
+			return false, nil
+		}
+		for index, value := range aValues {
+			equal, equalError := h.keysEqual(ctx, value, bValues[index])
+			if equalError != nil || !equal {
+				return false, equalError
+			}
+		}
+		return true, nil
+	case ValueId:
+		if _, getError := a.Get(magic_functions.Hash); getError != nil {
+			return false, nil
+		}
+		equalFunc, getError := a.Get(magic_functions.Equal)
+		if getError != nil {
+			return false, nil
+		}
+		result, callError := h.plasma.call(ctx, equalFunc, b)
+		if callError != nil {
+			return false, callError
+		}
+		return result.Bool(), nil
+	}
+	return false, nil
+}
+
+// lookup calls the keys protocol functions without holding the lock, so user defined
+// __hash__ and __equal__ can safely interact with the hash
+func (h *Hash) lookup(ctx *context, key *Value) (*hashEntry, uint64, error) {
+	entry, hash, _, lookupError := h.lookupBucket(ctx, key)
+	return entry, hash, lookupError
+}
+
+// lookupBucket also returns the bucket it scanned, so writers can check it was not changed meanwhile
+func (h *Hash) lookupBucket(ctx *context, key *Value) (*hashEntry, uint64, []*hashEntry, error) {
+	hash, hashError := h.hashOf(ctx, key)
+	if hashError != nil {
+		return nil, 0, nil, hashError
+	}
+	h.mutex.Lock()
+	bucket := h.buckets[hash]
+	h.mutex.Unlock()
+	for _, entry := range bucket {
+		equal, equalError := h.keysEqual(ctx, entry.key, key)
+		if equalError != nil {
+			return nil, 0, nil, equalError
+		}
+		if equal {
+			return entry, hash, bucket, nil
+		}
+	}
+	return nil, hash, bucket, nil
+}
+
+// sameBucket reports if the bucket still holds the entries scanned by lookupBucket, removals
+// replace the slice and insertions make it longer
+func sameBucket(current, scanned []*hashEntry) bool {
+	if len(current) != len(scanned) {
+		return false
+	}
+	return len(current) == 0 || &current[0] == &scanned[0]
+}
+
+func (h *Hash) insert(hash uint64, key, value *Value) {
+	entry := &hashEntry{
+		key:      key,
+		value:    value,
+		hash:     hash,
+		previous: h.last,
+	}
+	if h.last != nil {
+		h.last.next = entry
+	} else {
+		h.first = entry
+	}
+	h.last = entry
+	h.buckets[hash] = append(h.buckets[hash], entry)
+	h.size++
+}
+
+func (h *Hash) remove(entry *hashEntry) {
+	entry.removed = true
+	if entry.previous != nil {
+		entry.previous.next = entry.next
+	} else {
+		h.first = entry.next
+	}
+	if entry.next != nil {
+		entry.next.previous = entry.previous
+	} else {
+		h.last = entry.previous
+	}
+	// A new slice is created so buckets obtained by lookup are never modified
+	bucket := h.buckets[entry.hash]
+	newBucket := make([]*hashEntry, 0, len(bucket))
+	for _, bucketEntry := range bucket {
+		if bucketEntry != entry {
+			newBucket = append(newBucket, bucketEntry)
+		}
+	}
+	if len(newBucket) == 0 {
+		delete(h.buckets, entry.hash)
+	} else {
+		h.buckets[entry.hash] = newBucket
+	}
+	h.size--
 }
 
 func (h *Hash) Size() int64 {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
-	return int64(len(h.internalMap))
+	return h.size
 }
 
 func (h *Hash) Set(key, value *Value) error {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
-	switch key.TypeId() {
-	case StringId:
-		h.internalMap[createHashString(string(key.GetBytes()))] = value
-	case BytesId:
-		h.internalMap[createHashString(key.GetBytes())] = value
-	case BoolId:
-		h.internalMap[createHashString(key.GetBool())] = value
-	case IntId:
-		h.internalMap[createHashString(key.GetInt64())] = value
-	case FloatId:
-		h.internalMap[createHashString(key.GetFloat64())] = value
-	default:
-		return NotHashable
+	return h.set(nil, key, value)
+}
+
+// set is Set calling the keys protocol functions as part of the running execution
+func (h *Hash) set(ctx *context, key, value *Value) error {
+	for {
+		entry, hash, bucket, lookupError := h.lookupBucket(ctx, key)
+		if lookupError != nil {
+			return lookupError
+		}
+		h.mutex.Lock()
+		if entry != nil && !entry.removed {
+			entry.value = value
+			h.mutex.Unlock()
+			return nil
+		}
+		// Another goroutine may have inserted an equal key since the lookup, scan again
+		if sameBucket(h.buckets[hash], bucket) {
+			h.insert(hash, key, value)
+			h.mutex.Unlock()
+			return nil
+		}
+		h.mutex.Unlock()
 	}
-	return nil
 }
 
 func (h *Hash) Get(key *Value) (*Value, error) {
+	return h.get(nil, key)
+}
+
+func (h *Hash) get(ctx *context, key *Value) (*Value, error) {
+	entry, _, lookupError := h.lookup(ctx, key)
+	if lookupError != nil {
+		return nil, lookupError
+	}
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
-	switch key.TypeId() {
-	case StringId:
-		return h.internalMap[createHashString(string(key.GetBytes()))], nil
-	case BytesId:
-		return h.internalMap[createHashString(key.GetBytes())], nil
-	case BoolId:
-		return h.internalMap[createHashString(key.GetBool())], nil
-	case IntId:
-		return h.internalMap[createHashString(key.GetInt64())], nil
-	case FloatId:
-		return h.internalMap[createHashString(key.GetFloat64())], nil
+	if entry == nil || entry.removed {
+		return nil, KeyNotFound
 	}
-	return nil, NotHashable
+	return entry.value, nil
 }
 
 func (h *Hash) Del(key *Value) error {
+	return h.del(nil, key)
+}
+
+func (h *Hash) del(ctx *context, key *Value) error {
+	entry, _, lookupError := h.lookup(ctx, key)
+	if lookupError != nil {
+		return lookupError
+	}
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
-	switch key.TypeId() {
-	case StringId:
-		delete(h.internalMap, createHashString(string(key.GetBytes())))
-	case BytesId:
-		delete(h.internalMap, createHashString(key.GetBytes()))
-	case BoolId:
-		delete(h.internalMap, createHashString(key.GetBool()))
-	case IntId:
-		delete(h.internalMap, createHashString(key.GetInt64()))
-	case FloatId:
-		delete(h.internalMap, createHashString(key.GetFloat64()))
-	default:
-		return NotHashable
+	if entry != nil && !entry.removed {
+		h.remove(entry)
 	}
 	return nil
 }
@@ -87,39 +306,67 @@ func (h *Hash) Copy() *Hash {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 	result := &Hash{
-		mutex:       &sync.Mutex{},
-		internalMap: make(map[string]*Value, len(h.internalMap)),
+		plasma:  h.plasma,
+		mutex:   &sync.Mutex{},
+		buckets: make(map[uint64][]*hashEntry, len(h.buckets)),
 	}
-	for key, value := range h.internalMap {
-		result.internalMap[key] = value
+	for entry := h.first; entry != nil; entry = entry.next {
+		result.insert(entry.hash, entry.key, entry.value)
 	}
 	return result
 }
 
 func (h *Hash) In(key *Value) (bool, error) {
+	return h.contains(nil, key)
+}
+
+func (h *Hash) contains(ctx *context, key *Value) (bool, error) {
+	entry, _, lookupError := h.lookup(ctx, key)
+	if lookupError != nil {
+		return false, lookupError
+	}
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
-	var found bool
-	switch key.TypeId() {
-	case StringId:
-		_, found = h.internalMap[createHashString(string(key.GetBytes()))]
-	case BytesId:
-		_, found = h.internalMap[createHashString(key.GetBytes())]
-	case BoolId:
-		_, found = h.internalMap[createHashString(key.GetBool())]
-	case IntId:
-		_, found = h.internalMap[createHashString(key.GetInt64())]
-	case FloatId:
-		_, found = h.internalMap[createHashString(key.GetFloat64())]
-	default:
-		return false, NotHashable
+	return entry != nil && !entry.removed, nil
+}
+
+func (h *Hash) Keys() []*Value {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	result := make([]*Value, 0, h.size)
+	for entry := h.first; entry != nil; entry = entry.next {
+		result = append(result, entry.key)
 	}
-	return found, nil
+	return result
+}
+
+func (h *Hash) Values() []*Value {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	result := make([]*Value, 0, h.size)
+	for entry := h.first; entry != nil; entry = entry.next {
+		result = append(result, entry.value)
+	}
+	return result
+}
+
+func (h *Hash) Items() []*KeyValue {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	result := make([]*KeyValue, 0, h.size)
+	for entry := h.first; entry != nil; entry = entry.next {
+		result = append(result, &KeyValue{
+			Key:   entry.key,
+			Value: entry.value,
+		})
+	}
+	return result
 }
 
 func (plasma *Plasma) NewInternalHash() *Hash {
 	return &Hash{
-		mutex:       &sync.Mutex{},
-		internalMap: map[string]*Value{},
+		plasma:  plasma,
+		mutex:   &sync.Mutex{},
+		buckets: map[uint64][]*hashEntry{},
 	}
 }
@@ -1,10 +1,13 @@
 package vm
 
-import magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+import (
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+)
 
 func (plasma *Plasma) hashClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.Hash, []Parameter{param("hash", HashId)}, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewHash(argument[0].GetHash()), nil
 	}))
 	return class
@@ -13,6 +16,8 @@ func (plasma *Plasma) hashClass() *Value {
 /*
 NewHash magic function:
 In                  __in__
+Equal               __equal__
+NotEqual            __not_equal__
 Length              __len__
 Bool                __bool__
 String              __string__
@@ -21,64 +26,135 @@ Get                 __get__
 Set                 __set__
 Del				 	__del__
 Copy                __copy__
+Iter                __iter__
+Keys				keys
+Values				values
+Items				items
+GetDefault			get
+SetDefault			set_default
+Update				update
+Pop					pop
 */
 func (plasma *Plasma) NewHash(hash *Hash) *Value {
 	result := plasma.NewValue(plasma.rootSymbols, HashId, plasma.hash)
 	result.SetAny(hash)
-	result.Set(magic_functions.In, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			in, inError := result.GetHash().In(argument[0])
-			return plasma.NewBool(in), inError
-		},
-	))
-	result.Set(magic_functions.Length, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewInt(result.GetHash().Size()), nil
-		},
-	))
-	result.Set(magic_functions.Bool, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(result.Bool()), nil
-		},
-	))
-	result.Set(magic_functions.String, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewString([]byte(result.String())), nil
-		},
-	))
-	result.Set(magic_functions.Bytes, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBytes([]byte(result.String())), nil
-		},
-	))
-	result.Set(magic_functions.Get, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result.GetHash().Get(argument[0])
-		},
-	))
-	result.Set(magic_functions.Set, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.none, result.GetHash().Set(argument[0], argument[1])
-		},
-	))
-	result.Set(magic_functions.Del, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.none, result.GetHash().Del(argument[0])
-		},
-	))
-	result.Set(magic_functions.Copy, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewHash(result.GetHash().Copy()), nil
-		},
-	))
+	plasma.define(result.vtable, magic_functions.In, []Parameter{param("key")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		in, inError := result.GetHash().contains(ctx, argument[0])
+		return plasma.NewBool(in), inError
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		equal, equalError := result.equals(ctx, argument[0], nil)
+		if equalError != nil {
+			return nil, equalError
+		}
+		return plasma.NewBool(equal), nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		equal, equalError := result.equals(ctx, argument[0], nil)
+		if equalError != nil {
+			return nil, equalError
+		}
+		return plasma.NewBool(!equal), nil
+	})
+	plasma.define(result.vtable, magic_functions.Length, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(result.GetHash().Size()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result.Bool()), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s, renderError := plasma.repr(ctx, result)
+		if renderError != nil {
+			return nil, renderError
+		}
+		return plasma.NewString([]byte(s)), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bytes, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBytes([]byte(result.String())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Get, []Parameter{param("key")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result.GetHash().get(ctx, argument[0])
+	})
+	plasma.define(result.vtable, magic_functions.Set, []Parameter{param("key"), param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, result.GetHash().set(ctx, argument[0], argument[1])
+	})
+	plasma.define(result.vtable, magic_functions.Del, []Parameter{param("key")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, result.GetHash().del(ctx, argument[0])
+	})
+	plasma.define(result.vtable, magic_functions.Copy, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewHash(result.GetHash().Copy()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Iter, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		keys := result.GetHash().Keys()
+		iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
+		iter.SetAny(int64(0))
+		plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewBool(iter.GetInt64() < int64(len(keys))), nil
+		})
+		plasma.define(iter.vtable, magic_functions.Next, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			index := iter.GetInt64()
+			iter.SetAny(index + 1)
+			if index < int64(len(keys)) {
+				return keys[index], nil
+			}
+			return plasma.none, nil
+		})
+		return iter, nil
+	})
+	plasma.define(result.vtable, magic_functions.Keys, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewTuple(result.GetHash().Keys()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Values, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewTuple(result.GetHash().Values()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Items, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		items := result.GetHash().Items()
+		values := make([]*Value, 0, len(items))
+		for _, item := range items {
+			values = append(values, plasma.NewTuple([]*Value{item.Key, item.Value}))
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.GetDefault, []Parameter{param("key"), optional("default")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		value, getError := result.GetHash().get(ctx, argument[0])
+		if getError == KeyNotFound {
+			if len(argument) > 1 {
+				return argument[1], nil
+			}
+			return plasma.none, nil
+		}
+		return value, getError
+	})
+	plasma.define(result.vtable, magic_functions.SetDefault, []Parameter{param("key"), param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		hash := result.GetHash()
+		value, getError := hash.get(ctx, argument[0])
+		if getError != KeyNotFound {
+			return value, getError
+		}
+		return argument[1], hash.set(ctx, argument[0], argument[1])
+	})
+	plasma.define(result.vtable, magic_functions.Update, []Parameter{param("hash", HashId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if argument[0].TypeId() != HashId {
+			return nil, NotOperable
+		}
+		hash := result.GetHash()
+		for _, item := range argument[0].GetHash().Items() {
+			setError := hash.set(ctx, item.Key, item.Value)
+			if setError != nil {
+				return nil, setError
+			}
+		}
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Pop, []Parameter{param("key"), optional("default")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		hash := result.GetHash()
+		value, getError := hash.get(ctx, argument[0])
+		if getError == KeyNotFound && len(argument) > 1 {
+			return argument[1], nil
+		} else if getError != nil {
+			return nil, getError
+		}
+		return value, hash.del(ctx, argument[0])
+	})
 	return result
 }
@@ -1,7 +1,8 @@
 package vm
 
 import (
-	"bufio"
+	"io"
+
 	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
 	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
 )
@@ -9,25 +10,40 @@ import (
 func (plasma *Plasma) init() {
 	// On Demand values
 	plasma.onDemand = map[string]func(*Value) *Value{
+		magic_functions.Repr: func(self *Value) *Value {
+			return plasma.newBuiltIn(
+				self.vtable,
+				Signature{Name: magic_functions.Repr, Parameters: noParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
+					s, renderError := plasma.repr(ctx, self)
+					if renderError != nil {
+						return nil, renderError
+					}
+					return plasma.NewString([]byte(s)), nil
+				},
+			)
+		},
 		magic_functions.Equal: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(
+			return plasma.newBuiltIn(
 				self.vtable,
-				func(argument ...*Value) (*Value, error) {
+				Signature{Name: magic_functions.Equal, Parameters: otherParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					return plasma.NewBool(self == argument[0]), nil
 				},
 			)
 		},
 		magic_functions.NotEqual: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(
+			return plasma.newBuiltIn(
 				self.vtable,
-				func(argument ...*Value) (*Value, error) {
+				Signature{Name: magic_functions.NotEqual, Parameters: otherParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					return plasma.NewBool(self != argument[0]), nil
 				},
 			)
 		},
 		magic_functions.And: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.And, Parameters: otherParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					if self.Bool() && argument[0].Bool() {
 						return plasma.true, nil
 					}
@@ -35,8 +51,8 @@ func (plasma *Plasma) init() {
 				})
 		},
 		magic_functions.Or: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.Or, Parameters: otherParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					if self.Bool() || argument[0].Bool() {
 						return plasma.true, nil
 					}
@@ -44,8 +60,8 @@ func (plasma *Plasma) init() {
 				})
 		},
 		magic_functions.Xor: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.Xor, Parameters: otherParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					if self.Bool() != argument[0].Bool() {
 						return plasma.true, nil
 					}
@@ -53,8 +69,8 @@ func (plasma *Plasma) init() {
 				})
 		},
 		magic_functions.Is: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.Is, Parameters: []Parameter{param("class")}},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					class := argument[0]
 					switch class.TypeId() {
 					case BuiltInClassId, ClassId:
@@ -64,8 +80,8 @@ func (plasma *Plasma) init() {
 				})
 		},
 		magic_functions.Implements: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.Implements, Parameters: []Parameter{param("class")}},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					class := argument[0]
 					switch class.TypeId() {
 					case BuiltInClassId, ClassId:
@@ -75,27 +91,28 @@ func (plasma *Plasma) init() {
 				})
 		},
 		magic_functions.Bool: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.Bool, Parameters: noParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					return plasma.NewBool(self.Bool()), nil
 				})
 		},
 		magic_functions.Class: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.Class, Parameters: noParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					return self.GetClass(), nil
 				})
 		},
 		magic_functions.SubClasses: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(self.vtable,
-				func(argument ...*Value) (*Value, error) {
+			return plasma.newBuiltIn(self.vtable, Signature{Name: magic_functions.SubClasses, Parameters: noParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					return plasma.NewTuple(self.GetClass().GetClassInfo().Bases), nil
 				})
 		},
 		magic_functions.Iter: func(self *Value) *Value {
-			return plasma.NewBuiltInFunction(
+			return plasma.newBuiltIn(
 				self.vtable,
-				func(argument ...*Value) (*Value, error) {
+				Signature{Name: magic_functions.Iter, Parameters: noParameters},
+				func(ctx *context, argument ...*Value) (*Value, error) {
 					return self, nil
 				},
 			)
@@ -114,6 +131,7 @@ func (plasma *Plasma) init() {
 	plasma.array = plasma.arrayClass()
 	plasma.tuple = plasma.tupleClass()
 	plasma.hash = plasma.hashClass()
+	plasma.set = plasma.setClass()
 	// Init values
 	plasma.true = plasma.NewBool(true)
 	plasma.false = plasma.NewBool(false)
@@ -130,119 +148,268 @@ func (plasma *Plasma) init() {
 	plasma.rootSymbols.Set(special_symbols.Array, plasma.array)
 	plasma.rootSymbols.Set(special_symbols.Tuple, plasma.tuple)
 	plasma.rootSymbols.Set(special_symbols.Hash, plasma.hash)
+	plasma.rootSymbols.Set(special_symbols.Set, plasma.set)
 	plasma.rootSymbols.Set(special_symbols.Function, plasma.function)
 	plasma.rootSymbols.Set(special_symbols.Class, plasma.class)
+	plasma.rootSymbols.Set(special_symbols.Math, plasma.mathModule())
+	plasma.rootSymbols.Set(special_symbols.JSON, plasma.jsonModule())
+	plasma.rootSymbols.Set(special_symbols.Regex, plasma.regexModule())
+	plasma.rootSymbols.Set(special_symbols.Time, plasma.timeModule())
+	plasma.rootSymbols.Set(special_symbols.OS, plasma.osModule())
+	plasma.rootSymbols.Set(special_symbols.Subprocess, plasma.subprocessModule())
+	plasma.rootSymbols.Set(special_symbols.Random, plasma.randomModule())
 	/*
+		- stdin
+		- stdout
+		- stderr
 		- input
 		- print
 		- println
 		- range
+		- sorted
+		- min
+		- max
+		- sum
+		- map
+		- filter
+		- zip
+		- enumerate
+		- take
+		- skip
+		- chain
+		- reduce
+		- any
+		- all
+		- list
+		- pack
+		- unpack
+		- open
+		- read_file
+		- write_file
+		- list_dir
+		- exists
 	*/
-	plasma.rootSymbols.Set(special_symbols.Input, plasma.NewBuiltInFunction(plasma.rootSymbols,
-		func(argument ...*Value) (*Value, error) {
-			_, writeError := plasma.Stdout.Write([]byte(argument[0].String()))
-			if writeError != nil {
-				panic(writeError)
-			}
-			scanner := bufio.NewScanner(plasma.Stdin)
-			if scanner.Scan() {
-				return plasma.NewString(scanner.Bytes()), nil
-			}
-			return plasma.none, nil
-		},
-	))
-	plasma.rootSymbols.Set(special_symbols.Print, plasma.NewBuiltInFunction(plasma.rootSymbols,
-		func(argument ...*Value) (*Value, error) {
-			for index, arg := range argument {
-				if index != 0 {
-					_, writeError := plasma.Stdout.Write([]byte(" "))
-					if writeError != nil {
-						panic(writeError)
-					}
-				}
-				_, writeError := plasma.Stdout.Write([]byte(arg.String()))
-				if writeError != nil {
-					panic(writeError)
-				}
-
-			}
-			return plasma.none, nil
-		},
-	))
-	plasma.rootSymbols.Set(special_symbols.Println, plasma.NewBuiltInFunction(plasma.rootSymbols,
-		func(argument ...*Value) (*Value, error) {
-			for index, arg := range argument {
-				if index != 0 {
-					_, writeError := plasma.Stdout.Write([]byte(" "))
-					if writeError != nil {
-						panic(writeError)
-					}
-				}
-				_, writeError := plasma.Stdout.Write([]byte(arg.String()))
-				if writeError != nil {
-					panic(writeError)
-				}
+	plasma.rootSymbols.Set(special_symbols.Stdin, plasma.newInputStream())
+	plasma.rootSymbols.Set(special_symbols.Stdout, plasma.newOutputStream(func() io.Writer { return plasma.Stdout }))
+	plasma.rootSymbols.Set(special_symbols.Stderr, plasma.newOutputStream(func() io.Writer { return plasma.Stderr }))
+	plasma.define(plasma.rootSymbols, special_symbols.Input, []Parameter{optional("prompt")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if len(argument) > 0 {
+			if printError := plasma.printValues(ctx, argument[:1], ""); printError != nil {
+				return nil, printError
 			}
-			_, writeError := plasma.Stdout.Write([]byte("\n"))
-			if writeError != nil {
-				panic(writeError)
+			if flushError := flush(plasma.Stdout); flushError != nil {
+				return nil, flushError
 			}
+		}
+		line, ok, readError := plasma.readLine()
+		if readError != nil {
+			return nil, readError
+		}
+		if !ok {
 			return plasma.none, nil
-		},
-	))
-	plasma.rootSymbols.Set(special_symbols.Range, plasma.NewBuiltInFunction(plasma.rootSymbols,
-		func(argument ...*Value) (*Value, error) {
-			var (
-				start              = argument[0]
-				end                = argument[1]
-				intStep      int64 = 1
-				floatStep          = 1.0
-				useFloatStep       = start.TypeId() == FloatId || end.TypeId() == FloatId
-			)
-			if len(argument) == 3 {
-				step := argument[2]
-				intStep = step.Int()
-				floatStep = step.Float()
-				if !useFloatStep {
-					useFloatStep = step.TypeId() == FloatId
-				}
+		}
+		return plasma.NewString(line), nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Print, []Parameter{variadic("values")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, plasma.printValues(ctx, argument, "")
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Println, []Parameter{variadic("values")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, plasma.printValues(ctx, argument, "\n")
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Range, []Parameter{param("start", numberTypes...), param("end", numberTypes...), optional("step", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var (
+			start              = argument[0]
+			end                = argument[1]
+			intStep      int64 = 1
+			floatStep          = 1.0
+			useFloatStep       = start.TypeId() == FloatId || end.TypeId() == FloatId
+		)
+		if len(argument) == 3 {
+			step := argument[2]
+			intStep = step.Int()
+			floatStep = step.Float()
+			if !useFloatStep {
+				useFloatStep = step.TypeId() == FloatId
 			}
-			iter := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
-			if useFloatStep {
-				iter.SetAny(start.Float())
-				iter.Set(magic_functions.HasNext, plasma.NewBuiltInFunction(
-					iter.vtable,
-					func(_ ...*Value) (*Value, error) {
-						return plasma.NewBool(iter.GetFloat64() < end.Float()), nil
-					},
-				))
-				iter.Set(magic_functions.Next, plasma.NewBuiltInFunction(
-					iter.vtable,
-					func(_ ...*Value) (*Value, error) {
-						current := iter.GetFloat64()
-						// fmt.Println(current)
-						iter.SetAny(current + floatStep)
-						return plasma.NewFloat(current), nil
-					},
-				))
-			} else {
-				iter.SetAny(start.Int())
-				iter.Set(magic_functions.HasNext, plasma.NewBuiltInFunction(
-					iter.vtable,
-					func(_ ...*Value) (*Value, error) {
-						return plasma.NewBool(iter.GetInt64() < end.Int()), nil
-					},
-				))
-				iter.Set(magic_functions.Next, plasma.NewBuiltInFunction(
-					iter.vtable,
-					func(_ ...*Value) (*Value, error) {
-						current := iter.GetInt64()
-						iter.SetAny(current + intStep)
-						return plasma.NewInt(current), nil
-					},
-				))
+		}
+		iter := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+		if useFloatStep {
+			iter.SetAny(start.Float())
+			plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(_ *context, _ ...*Value) (*Value, error) {
+				return plasma.NewBool(iter.GetFloat64() < end.Float()), nil
+			})
+			plasma.define(iter.vtable, magic_functions.Next, noParameters, func(_ *context, _ ...*Value) (*Value, error) {
+				current := iter.GetFloat64()
+				// fmt.Println(current)
+				iter.SetAny(current + floatStep)
+				return plasma.NewFloat(current), nil
+			})
+		} else {
+			iter.SetAny(start.Int())
+			plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(_ *context, _ ...*Value) (*Value, error) {
+				return plasma.NewBool(iter.GetInt64() < end.Int()), nil
+			})
+			plasma.define(iter.vtable, magic_functions.Next, noParameters, func(_ *context, _ ...*Value) (*Value, error) {
+				current := iter.GetInt64()
+				iter.SetAny(current + intStep)
+				return plasma.NewInt(current), nil
+			})
+		}
+		return iter, nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Sorted, []Parameter{param("iterable"), optional("key"), optional("reverse")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var (
+			key     *Value
+			reverse bool
+		)
+		if len(argument) > 1 {
+			key = argument[1]
+		}
+		if len(argument) > 2 {
+			reverse = argument[2].Bool()
+		}
+		values, collectError := plasma.collect(ctx, argument[0])
+		if collectError != nil {
+			return nil, collectError
+		}
+		values = append([]*Value{}, values...)
+		sortError := plasma.sortValues(ctx, values, key, reverse)
+		if sortError != nil {
+			return nil, sortError
+		}
+		return plasma.NewArray(values), nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Min, []Parameter{param("iterable"), optional("key")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var key *Value
+		if len(argument) > 1 {
+			key = argument[1]
+		}
+		return plasma.extreme(ctx, argument[0], key, false)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Max, []Parameter{param("iterable"), optional("key")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var key *Value
+		if len(argument) > 1 {
+			key = argument[1]
+		}
+		return plasma.extreme(ctx, argument[0], key, true)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Sum, []Parameter{param("iterable"), optional("start")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		total := plasma.NewInt(0)
+		if len(argument) > 1 {
+			total = argument[1]
+		}
+		iterError := plasma.iterate(ctx, argument[0], func(value *Value) error {
+			var addError error
+			total, addError = plasma.callMethod(ctx, total, magic_functions.Add, value)
+			return addError
+		})
+		if iterError != nil {
+			return nil, iterError
+		}
+		return total, nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Map, []Parameter{param("function"), param("iterable"), variadic("iterables")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.lazyMap(ctx, argument[0], argument[1:])
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Filter, []Parameter{param("predicate"), param("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.lazyFilter(ctx, argument[0], argument[1])
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Zip, []Parameter{variadic("iterables")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.lazyZip(ctx, argument)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Enumerate, []Parameter{param("iterable"), optional("start", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var start int64
+		if len(argument) > 1 {
+			var startError error
+			start, startError = argument[1].Int64()
+			if startError != nil {
+				return nil, startError
 			}
-			return iter, nil
-		},
-	))
+		}
+		return plasma.lazyEnumerate(ctx, argument[0], start)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Take, []Parameter{param("iterable"), param("n", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n, intError := argument[1].Int64()
+		if intError != nil {
+			return nil, intError
+		}
+		return plasma.lazyTake(ctx, argument[0], n)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Skip, []Parameter{param("iterable"), param("n", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n, intError := argument[1].Int64()
+		if intError != nil {
+			return nil, intError
+		}
+		return plasma.lazySkip(ctx, argument[0], n)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Chain, []Parameter{variadic("iterables")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.lazyChain(argument), nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Reduce, []Parameter{param("function"), param("iterable"), optional("initial")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var initial *Value
+		if len(argument) > 2 {
+			initial = argument[2]
+		}
+		return plasma.reduce(ctx, argument[0], argument[1], initial)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Any, []Parameter{param("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.anyOrAll(ctx, argument[0], false)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.All, []Parameter{param("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.anyOrAll(ctx, argument[0], true)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.List, []Parameter{param("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		values, collectError := plasma.collect(ctx, argument[0])
+		if collectError != nil {
+			return nil, collectError
+		}
+		return plasma.NewArray(values), nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Pack, []Parameter{param("format", StringId), variadic("values")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		packed, packError := plasma.pack(argument[0].String(), argument[1:])
+		if packError != nil {
+			return nil, packError
+		}
+		return plasma.NewBytes(packed), nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Unpack, []Parameter{param("format", StringId), param("data", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		values, unpackError := plasma.unpack(argument[0].String(), argument[1].GetBytes())
+		if unpackError != nil {
+			return nil, unpackError
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Open, []Parameter{param("path", StringId), optional("mode", StringId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		mode := "r"
+		if len(argument) > 1 {
+			mode = argument[1].String()
+		}
+		return plasma.openFile(argument[0].String(), mode)
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.ReadFile, []Parameter{param("path", StringId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.readFile(argument[0].String())
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.WriteFile, []Parameter{param("path", StringId), param("contents")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[1].TypeId() {
+		case StringId, BytesId:
+			return plasma.none, plasma.writeFile(argument[0].String(), argument[1].GetBytes())
+		}
+		return nil, NotOperable
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.ListDir, []Parameter{optional("path", StringId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if len(argument) == 0 {
+			return plasma.listDir(".")
+		}
+		return plasma.listDir(argument[0].String())
+	})
+	plasma.define(plasma.rootSymbols, special_symbols.Exists, []Parameter{param("path", StringId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.exists(argument[0].String())
+	})
+	// The built-ins are shared by every scope, so the scripts can not modify them
+	for _, value := range plasma.rootSymbols.locals() {
+		value.freeze()
+	}
+	plasma.true.freeze()
+	plasma.false.freeze()
+	plasma.none.freeze()
 }
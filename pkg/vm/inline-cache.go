@@ -0,0 +1,64 @@
+package vm
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	inlineCacheEntry struct {
+		vtable  *Symbols
+		version uint64
+		result  *Value
+	}
+	/*
+		inlineCache maps the offset of a Selector instruction to the last receiver layout it resolved.
+		An entry is only valid while the receiver virtual table keeps the same version, any Set or Del
+		over it (SelectorAssign, DeleteSelector, class body execution) invalidates it.
+	*/
+	inlineCache struct {
+		mutex   *sync.Mutex
+		entries map[int64]*inlineCacheEntry
+	}
+)
+
+func newInlineCache() *inlineCache {
+	return &inlineCache{
+		mutex:   &sync.Mutex{},
+		entries: map[int64]*inlineCacheEntry{},
+	}
+}
+
+func (cache *inlineCache) lookup(offset int64, receiver *Value) (*Value, bool) {
+	if cache == nil {
+		return nil, false
+	}
+	cache.mutex.Lock()
+	entry, found := cache.entries[offset]
+	cache.mutex.Unlock()
+	if !found ||
+		entry.vtable != receiver.vtable ||
+		entry.version != atomic.LoadUint64(&receiver.vtable.version) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+func (cache *inlineCache) store(offset int64, receiver *Value, symbol string, result *Value) {
+	if cache == nil {
+		return
+	}
+	// Symbols resolved by the parents of the receiver are not cached since
+	// their tables could be shadowed without touching the receiver version
+	local, version, found := receiver.vtable.getLocal(symbol)
+	if !found || local != result {
+		return
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.entries[offset] = &inlineCacheEntry{
+		vtable:  receiver.vtable,
+		version: version,
+		result:  result,
+	}
+}
@@ -1,15 +1,19 @@
 package vm
 
 import (
-	"bytes"
 	"encoding/binary"
 	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
 	"math"
+	"math/big"
 )
 
 func (plasma *Plasma) integerClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.Int, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if argument[0].TypeId() == IntId {
+			return plasma.NewBigInt(argument[0].GetBigInt()), nil
+		}
 		return plasma.NewInt(argument[0].Int()), nil
 	}))
 	return class
@@ -49,341 +53,271 @@ FromBig				from_big
 FromLittle			from_little
 */
 func (plasma *Plasma) NewInt(i int64) *Value {
+	return plasma.newInteger(i)
+}
+
+// NewBigInt creates an Int backed by math/big, values inside the int64 range are stored as int64
+func (plasma *Plasma) NewBigInt(i *big.Int) *Value {
+	if i.IsInt64() {
+		return plasma.newInteger(i.Int64())
+	}
+	return plasma.newInteger(new(big.Int).Set(i))
+}
+
+func (plasma *Plasma) newInteger(i any) *Value {
 	result := plasma.NewValue(plasma.rootSymbols, IntId, plasma.int)
 	result.SetAny(i)
-	result.Set(magic_functions.Positive,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return result, nil
-			},
-		))
-	result.Set(magic_functions.Negative,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return plasma.NewInt(-result.GetInt64()), nil
-			},
-		))
-	result.Set(magic_functions.NegateBits,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return plasma.NewInt(^result.GetInt64()), nil
-			},
-		))
-	result.Set(magic_functions.Equal,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId, FloatId:
-					return plasma.NewBool(result.Equal(argument[0])), nil
-				}
-				return plasma.false, nil
-			},
-		))
-	result.Set(magic_functions.NotEqual,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId, FloatId:
-					return plasma.NewBool(!result.Equal(argument[0])), nil
-				}
-				return plasma.true, nil
-			},
-		))
-	result.Set(magic_functions.GreaterThan,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewBool(result.Int() > argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewBool(result.Float() > argument[0].Float()), nil
-				}
-				return nil, NotComparable
-			},
-		))
-	result.Set(magic_functions.GreaterOrEqualThan,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewBool(result.Int() >= argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewBool(result.Float() >= argument[0].Float()), nil
-				}
-				return nil, NotComparable
-			},
-		))
-	result.Set(magic_functions.LessThan,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewBool(result.Int() < argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewBool(result.Float() < argument[0].Float()), nil
-				}
-				return nil, NotComparable
-			},
-		))
-	result.Set(magic_functions.LessOrEqualThan,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewBool(result.Int() <= argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewBool(result.Float() <= argument[0].Float()), nil
-				}
-				return nil, NotComparable
-			},
-		))
-	result.Set(magic_functions.BitwiseOr,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() | argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewInt(int64(uint64(result.Int()) | math.Float64bits(argument[0].Float()))), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.BitwiseXor,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() ^ argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewInt(int64(uint64(result.Int()) ^ math.Float64bits(argument[0].Float()))), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.BitwiseAnd,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() & argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewInt(int64(uint64(result.Int()) & math.Float64bits(argument[0].Float()))), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.BitwiseLeft,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() << argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewInt(int64(uint64(result.Int()) << math.Float64bits(argument[0].Float()))), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.BitwiseRight,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() >> argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewInt(int64(uint64(result.Int()) >> math.Float64bits(argument[0].Float()))), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.Add,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() + argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewFloat(result.Float() + argument[0].Float()), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.Sub,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() - argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewFloat(result.Float() - argument[0].Float()), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.Mul,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() * argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewFloat(result.Float() * argument[0].Float()), nil
-				case StringId:
-					s := argument[0].GetBytes()
-					times := result.GetInt64()
-					return plasma.NewString(bytes.Repeat(s, int(times))), nil
-				case BytesId:
-					s := argument[0].GetBytes()
-					times := result.GetInt64()
-					return plasma.NewBytes(bytes.Repeat(s, int(times))), nil
-				case ArrayId:
-					times := result.GetInt64()
-					currentValues := argument[0].GetValues()
-					newValues := make([]*Value, 0, int64(len(currentValues))*times)
-					for t := int64(0); t < times; t++ {
-						for _, value := range currentValues {
-							newValues = append(newValues, value)
-						}
-					}
-					return plasma.NewArray(newValues), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.Div,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId, FloatId:
-					return plasma.NewFloat(result.Float() / argument[0].Float()), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.FloorDiv,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId, FloatId:
-					return plasma.NewInt(result.Int() / argument[0].Int()), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.Modulus,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					return plasma.NewInt(result.Int() % argument[0].Int()), nil
-				case FloatId:
-					return plasma.NewFloat(math.Mod(result.Float(), argument[0].Float())), nil
-				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.PowerOf,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				switch argument[0].TypeId() {
-				case IntId:
-					times := argument[0].Int()
-					value := result.Int()
-					v := int64(1)
-					for t := int64(0); t < times; t++ {
-						v *= value
-					}
-					return plasma.NewInt(v), nil
-				case FloatId:
-					return plasma.NewFloat(math.Pow(result.Float(), argument[0].Float())), nil
+	plasma.define(result.vtable, magic_functions.Positive, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Negative, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.integerNegative(result), nil
+	})
+	plasma.define(result.vtable, magic_functions.NegateBits, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.integerNegateBits(result), nil
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(result.Equal(argument[0])), nil
+		}
+		return plasma.false, nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewBool(!result.Equal(argument[0])), nil
+		}
+		return plasma.true, nil
+	})
+	plasma.define(result.vtable, magic_functions.GreaterThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.NewBool(compareIntegers(result, argument[0]) > 0), nil
+		case FloatId:
+			return plasma.NewBool(result.Float() > argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.GreaterOrEqualThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.NewBool(compareIntegers(result, argument[0]) >= 0), nil
+		case FloatId:
+			return plasma.NewBool(result.Float() >= argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.LessThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.NewBool(compareIntegers(result, argument[0]) < 0), nil
+		case FloatId:
+			return plasma.NewBool(result.Float() < argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.LessOrEqualThan, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.NewBool(compareIntegers(result, argument[0]) <= 0), nil
+		case FloatId:
+			return plasma.NewBool(result.Float() <= argument[0].Float()), nil
+		}
+		return nil, NotComparable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseOr, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerOr(result, argument[0]), nil
+		case FloatId:
+			i, intError := result.Int64()
+			if intError != nil {
+				return nil, intError
+			}
+			return plasma.NewInt(int64(uint64(i) | math.Float64bits(argument[0].Float()))), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseXor, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerXor(result, argument[0]), nil
+		case FloatId:
+			i, intError := result.Int64()
+			if intError != nil {
+				return nil, intError
+			}
+			return plasma.NewInt(int64(uint64(i) ^ math.Float64bits(argument[0].Float()))), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseAnd, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerAnd(result, argument[0]), nil
+		case FloatId:
+			i, intError := result.Int64()
+			if intError != nil {
+				return nil, intError
+			}
+			return plasma.NewInt(int64(uint64(i) & math.Float64bits(argument[0].Float()))), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseLeft, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerLeftShift(result, argument[0])
+		case FloatId:
+			i, intError := result.Int64()
+			if intError != nil {
+				return nil, intError
+			}
+			return plasma.NewInt(int64(uint64(i) << math.Float64bits(argument[0].Float()))), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.BitwiseRight, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerRightShift(result, argument[0])
+		case FloatId:
+			i, intError := result.Int64()
+			if intError != nil {
+				return nil, intError
+			}
+			return plasma.NewInt(int64(uint64(i) >> math.Float64bits(argument[0].Float()))), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Add, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerAdd(result, argument[0]), nil
+		case FloatId:
+			return plasma.NewFloat(result.Float() + argument[0].Float()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Sub, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerSub(result, argument[0]), nil
+		case FloatId:
+			return plasma.NewFloat(result.Float() - argument[0].Float()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Mul, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerMul(result, argument[0]), nil
+		case FloatId:
+			return plasma.NewFloat(result.Float() * argument[0].Float()), nil
+		case StringId:
+			repeated, repeatError := repeat(argument[0].GetBytes(), result)
+			if repeatError != nil {
+				return nil, repeatError
+			}
+			return plasma.NewString(repeated), nil
+		case BytesId:
+			repeated, repeatError := repeat(argument[0].GetBytes(), result)
+			if repeatError != nil {
+				return nil, repeatError
+			}
+			return plasma.NewBytes(repeated), nil
+		case ArrayId:
+			times := result.GetInt64()
+			currentValues := argument[0].GetValues()
+			newValues := make([]*Value, 0, int64(len(currentValues))*times)
+			for t := int64(0); t < times; t++ {
+				for _, value := range currentValues {
+					newValues = append(newValues, value)
 				}
-				return nil, NotOperable
-			},
-		))
-	result.Set(magic_functions.Bool,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return plasma.NewBool(result.GetInt64() != 0), nil
-			},
-		))
-	result.Set(magic_functions.String,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return plasma.NewString([]byte(result.String())), nil
-			},
-		))
-	result.Set(magic_functions.Int,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return result, nil
-			},
-		))
-	result.Set(magic_functions.Float,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return plasma.NewFloat(result.Float()), nil
-			},
-		))
-	result.Set(magic_functions.Copy,
-		plasma.NewBuiltInFunction(
-			result.vtable,
-			func(argument ...*Value) (*Value, error) {
-				return plasma.NewInt(result.GetInt64()), nil
-			},
-		))
-	result.Set(magic_functions.BigEndian, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			b := make([]byte, 8)
-			binary.BigEndian.PutUint64(b, uint64(result.Int()))
-			return plasma.NewBytes(b), nil
-		},
-	))
-	result.Set(magic_functions.LittleEndian, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			b := make([]byte, 8)
-			binary.LittleEndian.PutUint64(b, uint64(result.Int()))
-			return plasma.NewBytes(b), nil
-		},
-	))
-	result.Set(magic_functions.FromBig, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewInt(int64(binary.BigEndian.Uint64(argument[0].GetBytes()))), nil
-		},
-	))
-	result.Set(magic_functions.FromLittle, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewInt(int64(binary.LittleEndian.Uint64(argument[0].GetBytes()))), nil
-		},
-	))
+			}
+			return plasma.NewArray(newValues), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Div, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			return plasma.NewFloat(result.Float() / argument[0].Float()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.FloorDiv, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerQuo(result, argument[0])
+		case FloatId:
+			return plasma.NewInt(result.Int() / argument[0].Int()), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Modulus, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerRem(result, argument[0])
+		case FloatId:
+			return plasma.NewFloat(math.Mod(result.Float(), argument[0].Float())), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.PowerOf, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			return plasma.integerPow(result, argument[0])
+		case FloatId:
+			return plasma.NewFloat(math.Pow(result.Float(), argument[0].Float())), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result.Bool()), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(result.String())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Int, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Float, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(result.Float()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Copy, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBigInt(result.GetBigInt()), nil
+	})
+	plasma.define(result.vtable, magic_functions.BigEndian, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if result.isBigInt() {
+			return plasma.NewBytes(bigIntToBytes(result.GetBigInt())), nil
+		}
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(result.Int()))
+		return plasma.NewBytes(b), nil
+	})
+	plasma.define(result.vtable, magic_functions.LittleEndian, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if result.isBigInt() {
+			return plasma.NewBytes(reverseBytes(bigIntToBytes(result.GetBigInt()))), nil
+		}
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(result.Int()))
+		return plasma.NewBytes(b), nil
+	})
+	plasma.define(result.vtable, magic_functions.FromBig, []Parameter{param("contents", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		b := argument[0].GetBytes()
+		if len(b) == 8 {
+			return plasma.NewInt(int64(binary.BigEndian.Uint64(b))), nil
+		}
+		return plasma.NewBigInt(bigIntFromBytes(b)), nil
+	})
+	plasma.define(result.vtable, magic_functions.FromLittle, []Parameter{param("contents", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		b := argument[0].GetBytes()
+		if len(b) == 8 {
+			return plasma.NewInt(int64(binary.LittleEndian.Uint64(b))), nil
+		}
+		return plasma.NewBigInt(bigIntFromBytes(reverseBytes(b))), nil
+	})
 	return result
 }
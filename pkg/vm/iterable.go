@@ -0,0 +1,71 @@
+package vm
+
+import magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+
+func (plasma *Plasma) callMethod(ctx *context, value *Value, method string, argument ...*Value) (*Value, error) {
+	function, getError := value.Get(method)
+	if getError != nil {
+		return nil, getError
+	}
+	return plasma.call(ctx, function, argument...)
+}
+
+// iterableTypes are the built-in types implementing __iter__, objects are iterable when they define it
+var iterableTypes = []TypeId{StringId, BytesId, ArrayId, TupleId, HashId, SetId}
+
+// isIterable reports if iterate accepts the value. Every value has an on demand __iter__ returning
+// itself, so it only counts when the value is also an iterator
+func isIterable(value *Value) bool {
+	switch value.TypeId() {
+	case ArrayId, TupleId:
+		return true
+	}
+	if iter, getError := value.Get(magic_functions.Iter); getError == nil && !iter.isOnDemand() {
+		return true
+	}
+	_, getError := value.Get(magic_functions.HasNext)
+	return getError == nil
+}
+
+// iterate walks any value implementing __iter__, __has_next__ and __next__, arrays and tuples are walked directly
+func (plasma *Plasma) iterate(ctx *context, iterable *Value, callback func(value *Value) error) error {
+	switch iterable.TypeId() {
+	case ArrayId, TupleId:
+		for _, value := range iterable.GetValues() {
+			if callbackError := callback(value); callbackError != nil {
+				return callbackError
+			}
+		}
+		return nil
+	}
+	iter, iterError := plasma.callMethod(ctx, iterable, magic_functions.Iter)
+	if iterError != nil {
+		return iterError
+	}
+	for {
+		hasNext, hasNextError := plasma.callMethod(ctx, iter, magic_functions.HasNext)
+		if hasNextError != nil {
+			return hasNextError
+		}
+		if !hasNext.Bool() {
+			return nil
+		}
+		value, nextError := plasma.callMethod(ctx, iter, magic_functions.Next)
+		if nextError != nil {
+			return nextError
+		}
+		if callbackError := callback(value); callbackError != nil {
+			return callbackError
+		}
+	}
+}
+
+// collect returns the values produced by the iterable, arrays and tuples are copied
+func (plasma *Plasma) collect(ctx *context, iterable *Value) ([]*Value, error) {
+	var values []*Value
+	iterError := plasma.iterate(ctx, iterable, func(value *Value) error {
+		values = append(values, value)
+		return nil
+	})
+	return values, iterError
+}
@@ -0,0 +1,318 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+var (
+	InvalidJSON     = fmt.Errorf("invalid json")
+	NotSerializable = fmt.Errorf("not serializable")
+	CyclicStructure = fmt.Errorf("cyclic structure")
+)
+
+// MaxJSONIndent is the widest indent dumps accepts as a number of spaces
+const MaxJSONIndent = 16
+
+// jsonPosition converts a byte offset of the source to its line and column, both starting at 1
+func jsonPosition(source []byte, offset int64) (int, int) {
+	if offset > int64(len(source)) {
+		offset = int64(len(source))
+	}
+	before := source[:offset]
+	line := bytes.Count(before, []byte{'\n'}) + 1
+	column := int(offset) - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	return line, column
+}
+
+type jsonDecoder struct {
+	plasma  *Plasma
+	source  []byte
+	decoder *json.Decoder
+}
+
+func (d *jsonDecoder) fail(cause error) error {
+	offset := d.decoder.InputOffset()
+	var syntaxError *json.SyntaxError
+	if errors.As(cause, &syntaxError) && syntaxError.Offset > 0 && syntaxError.Offset < int64(len(d.source)) {
+		// The offset points after the invalid character
+		offset = syntaxError.Offset - 1
+	}
+	message := cause.Error()
+	if cause == io.EOF {
+		message = "unexpected end of input"
+	}
+	line, column := jsonPosition(d.source, offset)
+	return fmt.Errorf("%w: line %d column %d: %s", InvalidJSON, line, column, message)
+}
+
+func (d *jsonDecoder) number(n json.Number) (*Value, error) {
+	if i, parseError := n.Int64(); parseError == nil {
+		return d.plasma.NewInt(i), nil
+	}
+	if !strings.ContainsAny(n.String(), ".eE") {
+		i, ok := new(big.Int).SetString(n.String(), 10)
+		if ok {
+			return d.plasma.NewBigInt(i), nil
+		}
+	}
+	f, parseError := n.Float64()
+	if parseError != nil {
+		return nil, d.fail(parseError)
+	}
+	return d.plasma.NewFloat(f), nil
+}
+
+// value decodes token by token so objects keep the order of their keys
+func (d *jsonDecoder) value() (*Value, error) {
+	token, tokenError := d.decoder.Token()
+	if tokenError != nil {
+		return nil, d.fail(tokenError)
+	}
+	switch t := token.(type) {
+	case nil:
+		return d.plasma.none, nil
+	case bool:
+		return d.plasma.NewBool(t), nil
+	case json.Number:
+		return d.number(t)
+	case string:
+		return d.plasma.NewString([]byte(t)), nil
+	case json.Delim:
+		if t == '}' || t == ']' {
+			return nil, d.fail(fmt.Errorf("unexpected %q", rune(t)))
+		}
+		if t == '[' {
+			var values []*Value
+			for d.decoder.More() {
+				element, elementError := d.value()
+				if elementError != nil {
+					return nil, elementError
+				}
+				values = append(values, element)
+			}
+			if _, endError := d.decoder.Token(); endError != nil {
+				return nil, d.fail(endError)
+			}
+			return d.plasma.NewArray(values), nil
+		}
+		hash := d.plasma.NewInternalHash()
+		for d.decoder.More() {
+			key, keyError := d.decoder.Token()
+			if keyError != nil {
+				return nil, d.fail(keyError)
+			}
+			element, elementError := d.value()
+			if elementError != nil {
+				return nil, elementError
+			}
+			if setError := hash.Set(d.plasma.NewString([]byte(key.(string))), element); setError != nil {
+				return nil, setError
+			}
+		}
+		if _, endError := d.decoder.Token(); endError != nil {
+			return nil, d.fail(endError)
+		}
+		return d.plasma.NewHash(hash), nil
+	}
+	return nil, d.fail(fmt.Errorf("unexpected token %v", token))
+}
+
+func (plasma *Plasma) JSONLoads(source []byte) (*Value, error) {
+	d := &jsonDecoder{
+		plasma:  plasma,
+		source:  source,
+		decoder: json.NewDecoder(bytes.NewReader(source)),
+	}
+	d.decoder.UseNumber()
+	result, decodeError := d.value()
+	if decodeError != nil {
+		return nil, decodeError
+	}
+	if _, tokenError := d.decoder.Token(); tokenError != io.EOF {
+		if tokenError != nil {
+			return nil, d.fail(tokenError)
+		}
+		return nil, d.fail(fmt.Errorf("unexpected data after the value"))
+	}
+	return result, nil
+}
+
+type jsonEncoder struct {
+	indent   string
+	buffer   bytes.Buffer
+	visiting map[*Value]struct{}
+}
+
+func (e *jsonEncoder) newLine(depth int) {
+	if e.indent == "" {
+		return
+	}
+	e.buffer.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		e.buffer.WriteString(e.indent)
+	}
+}
+
+func (e *jsonEncoder) string(s []byte) {
+	encoder := json.NewEncoder(&e.buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(string(s))
+	// Encode terminates the value with a new line
+	e.buffer.Truncate(e.buffer.Len() - 1)
+}
+
+func (e *jsonEncoder) key(value *Value) error {
+	switch value.TypeId() {
+	case StringId:
+		e.string(value.GetBytes())
+		return nil
+	case IntId, FloatId, BoolId, NoneId:
+		var key bytes.Buffer
+		e.buffer, key = key, e.buffer
+		encodeError := e.encode(value, 0)
+		e.buffer, key = key, e.buffer
+		if encodeError != nil {
+			return encodeError
+		}
+		e.string(key.Bytes())
+		return nil
+	}
+	return fmt.Errorf("%w: hash keys must be String, Int, Float, Bool or none", NotSerializable)
+}
+
+func (e *jsonEncoder) enter(value *Value) error {
+	if _, found := e.visiting[value]; found {
+		return CyclicStructure
+	}
+	e.visiting[value] = struct{}{}
+	return nil
+}
+
+func (e *jsonEncoder) encode(value *Value, depth int) error {
+	switch value.TypeId() {
+	case NoneId:
+		e.buffer.WriteString("null")
+	case BoolId:
+		e.buffer.WriteString(value.String())
+	case IntId:
+		e.buffer.WriteString(value.String())
+	case FloatId:
+		f := value.GetFloat64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("%w: %v", NotSerializable, f)
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			// Keep the value a Float when it is loaded back
+			s += ".0"
+		}
+		e.buffer.WriteString(s)
+	case StringId:
+		e.string(value.GetBytes())
+	case ArrayId, TupleId:
+		if enterError := e.enter(value); enterError != nil {
+			return enterError
+		}
+		defer delete(e.visiting, value)
+		values := value.GetValues()
+		e.buffer.WriteByte('[')
+		for index, element := range values {
+			if index != 0 {
+				e.buffer.WriteByte(',')
+			}
+			e.newLine(depth + 1)
+			if encodeError := e.encode(element, depth+1); encodeError != nil {
+				return encodeError
+			}
+		}
+		if len(values) > 0 {
+			e.newLine(depth)
+		}
+		e.buffer.WriteByte(']')
+	case HashId:
+		if enterError := e.enter(value); enterError != nil {
+			return enterError
+		}
+		defer delete(e.visiting, value)
+		items := value.GetHash().Items()
+		e.buffer.WriteByte('{')
+		for index, item := range items {
+			if index != 0 {
+				e.buffer.WriteByte(',')
+			}
+			e.newLine(depth + 1)
+			if keyError := e.key(item.Key); keyError != nil {
+				return keyError
+			}
+			e.buffer.WriteByte(':')
+			if e.indent != "" {
+				e.buffer.WriteByte(' ')
+			}
+			if encodeError := e.encode(item.Value, depth+1); encodeError != nil {
+				return encodeError
+			}
+		}
+		if len(items) > 0 {
+			e.newLine(depth)
+		}
+		e.buffer.WriteByte('}')
+	default:
+		return NotSerializable
+	}
+	return nil
+}
+
+// JSONDumps encodes the value, a non empty indent pretty prints the result
+func (plasma *Plasma) JSONDumps(value *Value, indent string) ([]byte, error) {
+	e := &jsonEncoder{
+		indent:   indent,
+		visiting: map[*Value]struct{}{},
+	}
+	if encodeError := e.encode(value, 0); encodeError != nil {
+		return nil, encodeError
+	}
+	return e.buffer.Bytes(), nil
+}
+
+/*
+jsonModule functions:
+Dumps	dumps
+Loads	loads
+*/
+func (plasma *Plasma) jsonModule() *Value {
+	module := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.define(module.vtable, magic_functions.Dumps, []Parameter{param("value"), optional("indent", IntId, StringId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var indent string
+		if len(argument) > 1 {
+			switch argument[1].TypeId() {
+			case IntId:
+				width := argument[1].GetBigInt()
+				if width.Sign() < 0 || width.Cmp(big.NewInt(MaxJSONIndent)) > 0 {
+					return nil, fmt.Errorf("%w: indent %s is not between 0 and %d", InvalidRange, width, MaxJSONIndent)
+				}
+				indent = strings.Repeat(" ", int(width.Int64()))
+			case StringId:
+				indent = argument[1].String()
+			}
+		}
+		encoded, encodeError := plasma.JSONDumps(argument[0], indent)
+		if encodeError != nil {
+			return nil, encodeError
+		}
+		return plasma.NewString(encoded), nil
+	})
+	plasma.define(module.vtable, magic_functions.Loads, []Parameter{param("text", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.JSONLoads(argument[0].GetBytes())
+	})
+	return module
+}
@@ -0,0 +1,173 @@
+package vm
+
+import (
+	"math"
+	"math/big"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+// floatToInt converts the already rounded float to Int, promoting to big integers when needed
+func (plasma *Plasma) floatToInt(f float64) (*Value, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, NotOperable
+	}
+	if f >= math.MinInt64 && f < math.MaxInt64 {
+		return plasma.NewInt(int64(f)), nil
+	}
+	i, _ := big.NewFloat(f).Int(nil)
+	return plasma.NewBigInt(i), nil
+}
+
+func (plasma *Plasma) integerGcd(a, b *Value) (*Value, error) {
+	if a.TypeId() != IntId || b.TypeId() != IntId {
+		return nil, NotOperable
+	}
+	x := new(big.Int).Abs(a.GetBigInt())
+	y := new(big.Int).Abs(b.GetBigInt())
+	return plasma.NewBigInt(new(big.Int).GCD(nil, nil, x, y)), nil
+}
+
+func (plasma *Plasma) integerLcm(a, b *Value) (*Value, error) {
+	gcd, gcdError := plasma.integerGcd(a, b)
+	if gcdError != nil {
+		return nil, gcdError
+	}
+	if gcd.GetBigInt().Sign() == 0 {
+		return plasma.NewInt(0), nil
+	}
+	product := new(big.Int).Mul(a.GetBigInt(), b.GetBigInt())
+	product.Abs(product)
+	return plasma.NewBigInt(product.Quo(product, gcd.GetBigInt())), nil
+}
+
+/*
+mathModule functions:
+Sqrt	sqrt
+Pow		pow
+Exp		exp
+Log		log
+Log2	log2
+Log10	log10
+Sin		sin
+Cos		cos
+Tan		tan
+Asin	asin
+Acos	acos
+Atan	atan
+Atan2	atan2
+Floor	floor
+Ceil	ceil
+Round	round
+Abs		abs
+Hypot	hypot
+Gcd		gcd
+Lcm		lcm
+IsNaN	is_nan
+IsInf	is_inf
+Clamp	clamp
+Constants: pi, e, inf, nan
+*/
+func (plasma *Plasma) mathModule() *Value {
+	module := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	module.Set(magic_functions.Pi, plasma.NewFloat(math.Pi))
+	module.Set(magic_functions.E, plasma.NewFloat(math.E))
+	module.Set(magic_functions.Inf, plasma.NewFloat(math.Inf(1)))
+	module.Set(magic_functions.NaN, plasma.NewFloat(math.NaN()))
+	for name, function := range map[string]func(float64) float64{
+		magic_functions.Sqrt:  math.Sqrt,
+		magic_functions.Exp:   math.Exp,
+		magic_functions.Log2:  math.Log2,
+		magic_functions.Log10: math.Log10,
+		magic_functions.Sin:   math.Sin,
+		magic_functions.Cos:   math.Cos,
+		magic_functions.Tan:   math.Tan,
+		magic_functions.Asin:  math.Asin,
+		magic_functions.Acos:  math.Acos,
+		magic_functions.Atan:  math.Atan,
+	} {
+		function := function
+		plasma.define(module.vtable, name, []Parameter{param("x", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewFloat(function(argument[0].Float())), nil
+		})
+	}
+	for name, function := range map[string]func(float64, float64) float64{
+		magic_functions.Pow:   math.Pow,
+		magic_functions.Atan2: math.Atan2,
+		magic_functions.Hypot: math.Hypot,
+	} {
+		function := function
+		plasma.define(module.vtable, name, []Parameter{param("x", numberTypes...), param("y", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewFloat(function(argument[0].Float(), argument[1].Float())), nil
+		})
+	}
+	plasma.define(module.vtable, magic_functions.Log, []Parameter{param("x", numberTypes...), optional("base", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		result := math.Log(argument[0].Float())
+		if len(argument) > 1 {
+			result /= math.Log(argument[1].Float())
+		}
+		return plasma.NewFloat(result), nil
+	})
+	plasma.define(module.vtable, magic_functions.Floor, []Parameter{param("x", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if argument[0].TypeId() == IntId {
+			return argument[0], nil
+		}
+		return plasma.floatToInt(math.Floor(argument[0].Float()))
+	})
+	plasma.define(module.vtable, magic_functions.Ceil, []Parameter{param("x", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if argument[0].TypeId() == IntId {
+			return argument[0], nil
+		}
+		return plasma.floatToInt(math.Ceil(argument[0].Float()))
+	})
+	plasma.define(module.vtable, magic_functions.Round, []Parameter{param("x", numberTypes...), optional("digits", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if len(argument) < 2 {
+			if argument[0].TypeId() == IntId {
+				return argument[0], nil
+			}
+			return plasma.floatToInt(math.Round(argument[0].Float()))
+		}
+		scale := math.Pow(10, argument[1].Float())
+		return plasma.NewFloat(math.Round(argument[0].Float()*scale) / scale), nil
+	})
+	plasma.define(module.vtable, magic_functions.Abs, []Parameter{param("x", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if argument[0].TypeId() == IntId {
+			if compareIntegers(argument[0], plasma.NewInt(0)) < 0 {
+				return plasma.integerNegative(argument[0]), nil
+			}
+			return argument[0], nil
+		}
+		return plasma.NewFloat(math.Abs(argument[0].Float())), nil
+	})
+	plasma.define(module.vtable, magic_functions.Gcd, []Parameter{param("a", IntId), param("b", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.integerGcd(argument[0], argument[1])
+	})
+	plasma.define(module.vtable, magic_functions.Lcm, []Parameter{param("a", IntId), param("b", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.integerLcm(argument[0], argument[1])
+	})
+	plasma.define(module.vtable, magic_functions.IsNaN, []Parameter{param("x", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(math.IsNaN(argument[0].Float())), nil
+	})
+	plasma.define(module.vtable, magic_functions.IsInf, []Parameter{param("x", numberTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(math.IsInf(argument[0].Float(), 0)), nil
+	})
+	plasma.define(module.vtable, magic_functions.Clamp, []Parameter{param("value"), param("low"), param("high")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		value, low, high := argument[0], argument[1], argument[2]
+		less, lessError := plasma.lessThan(ctx, value, low)
+		if lessError != nil {
+			return nil, lessError
+		}
+		if less {
+			return low, nil
+		}
+		greater, lessError := plasma.lessThan(ctx, high, value)
+		if lessError != nil {
+			return nil, lessError
+		}
+		if greater {
+			return high, nil
+		}
+		return value, nil
+	})
+	return module
+}
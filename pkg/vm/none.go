@@ -1,10 +1,13 @@
 package vm
 
-import magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+import (
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+)
 
 func (plasma *Plasma) noneClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.None, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewNone(), nil
 	}))
 	return class
@@ -20,17 +23,11 @@ func (plasma *Plasma) NewNone() *Value {
 		return plasma.none
 	}
 	result := plasma.NewValue(plasma.rootSymbols, NoneId, plasma.noneType)
-	result.Set(magic_functions.Bool, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.false, nil
-		},
-	))
-	result.Set(magic_functions.String, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewString([]byte(result.String())), nil
-		},
-	))
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.false, nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(result.String())), nil
+	})
 	return result
 }
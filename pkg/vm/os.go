@@ -0,0 +1,233 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+var OSDisabled = fmt.Errorf("os access is disabled")
+
+type (
+	// ProcessResult is the outcome of a finished subprocess, Status is its exit code
+	ProcessResult struct {
+		Stdout, Stderr []byte
+		Status         int
+	}
+	/*
+		OS is what the os and subprocess modules can see of the process running the VM.
+		The VM has no OS until the host sets one with SetOS, HostOS exposes the real process.
+	*/
+	OS interface {
+		Args() []string
+		LookupEnv(key string) (string, bool)
+		Setenv(key, value string) error
+		Environ() []string
+		Getwd() (string, error)
+		Run(argv []string, stdin []byte) (*ProcessResult, error)
+	}
+	// ExitError is returned by the execution when the script calls os.exit, after its defer and END code ran
+	ExitError struct {
+		Code int
+	}
+)
+
+func (exit *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", exit.Code)
+}
+
+type hostOS struct {
+	args []string
+}
+
+// HostOS gives scripts access to the environment of the process, args are the ones returned by os.args
+func HostOS(args []string) OS {
+	return &hostOS{args: args}
+}
+
+func (host *hostOS) Args() []string {
+	return host.args
+}
+
+func (host *hostOS) LookupEnv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (host *hostOS) Setenv(key, value string) error {
+	return os.Setenv(key, value)
+}
+
+func (host *hostOS) Environ() []string {
+	return os.Environ()
+}
+
+func (host *hostOS) Getwd() (string, error) {
+	return os.Getwd()
+}
+
+func (host *hostOS) Run(argv []string, stdin []byte) (*ProcessResult, error) {
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("%w: empty argv", NotOperable)
+	}
+	var stdout, stderr bytes.Buffer
+	command := exec.Command(argv[0], argv[1:]...)
+	command.Stdin = bytes.NewReader(stdin)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	runError := command.Run()
+	var exitError *exec.ExitError
+	if runError != nil && !errors.As(runError, &exitError) {
+		return nil, runError
+	}
+	return &ProcessResult{
+		Stdout: stdout.Bytes(),
+		Stderr: stderr.Bytes(),
+		Status: command.ProcessState.ExitCode(),
+	}, nil
+}
+
+func (plasma *Plasma) SetOS(host OS) {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	plasma.os = host
+}
+
+func (plasma *Plasma) OS() (OS, error) {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	if plasma.os == nil {
+		return nil, OSDisabled
+	}
+	return plasma.os, nil
+}
+
+// defineOS sets a built-in that can only run when the host granted os access
+func (plasma *Plasma) defineOS(symbols *Symbols, name string, parameters []Parameter, callback func(ctx *context, host OS, argument ...*Value) (*Value, error)) {
+	plasma.define(symbols, name, parameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		host, disabledError := plasma.OS()
+		if disabledError != nil {
+			return nil, disabledError
+		}
+		return callback(ctx, host, argument...)
+	})
+}
+
+func (plasma *Plasma) stringTuple(values []string) *Value {
+	result := make([]*Value, 0, len(values))
+	for _, value := range values {
+		result = append(result, plasma.NewString([]byte(value)))
+	}
+	return plasma.NewTuple(result)
+}
+
+/*
+envObject methods:
+GetDefault	get
+SetEnv		set
+ListEnv		list
+*/
+func (plasma *Plasma) envObject() *Value {
+	env := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.defineOS(env.vtable, magic_functions.GetDefault, []Parameter{param("name", StringId), optional("default")}, func(ctx *context, host OS, argument ...*Value) (*Value, error) {
+		value, found := host.LookupEnv(argument[0].String())
+		if found {
+			return plasma.NewString([]byte(value)), nil
+		}
+		if len(argument) > 1 {
+			return argument[1], nil
+		}
+		return plasma.none, nil
+	})
+	plasma.defineOS(env.vtable, magic_functions.SetEnv, []Parameter{param("name", StringId), param("value", StringId)}, func(ctx *context, host OS, argument ...*Value) (*Value, error) {
+		return plasma.none, host.Setenv(argument[0].String(), argument[1].String())
+	})
+	plasma.defineOS(env.vtable, magic_functions.ListEnv, noParameters, func(ctx *context, host OS, argument ...*Value) (*Value, error) {
+		environ := host.Environ()
+		sort.Strings(environ)
+		variables := plasma.NewInternalHash()
+		for _, variable := range environ {
+			key, value, _ := strings.Cut(variable, "=")
+			setError := variables.Set(plasma.NewString([]byte(key)), plasma.NewString([]byte(value)))
+			if setError != nil {
+				return nil, setError
+			}
+		}
+		return plasma.NewHash(variables), nil
+	})
+	return env
+}
+
+/*
+osModule functions:
+Args	args
+Env		env
+Cwd		cwd
+Exit	exit
+Exiting unwinds the whole execution running the pending defer and END code,
+the host receives the code through ExitError.
+*/
+func (plasma *Plasma) osModule() *Value {
+	module := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	module.Set(magic_functions.Env, plasma.envObject())
+	plasma.defineOS(module.vtable, magic_functions.Args, noParameters, func(ctx *context, host OS, argument ...*Value) (*Value, error) {
+		return plasma.stringTuple(host.Args()), nil
+	})
+	plasma.defineOS(module.vtable, magic_functions.Cwd, noParameters, func(ctx *context, host OS, argument ...*Value) (*Value, error) {
+		cwd, cwdError := host.Getwd()
+		if cwdError != nil {
+			return nil, cwdError
+		}
+		return plasma.NewString([]byte(cwd)), nil
+	})
+	plasma.defineOS(module.vtable, magic_functions.Exit, []Parameter{optional("code", IntId)}, func(ctx *context, host OS, argument ...*Value) (*Value, error) {
+		code := 0
+		if len(argument) > 0 {
+			code = int(argument[0].Int())
+		}
+		return nil, &ExitError{Code: code}
+	})
+	return module
+}
+
+/*
+subprocessModule functions:
+Run		run
+run(argv[, stdin]) waits for the process and returns an object with its stdout, stderr and status
+*/
+func (plasma *Plasma) subprocessModule() *Value {
+	module := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.defineOS(module.vtable, magic_functions.Run, []Parameter{param("argv"), optional("stdin", StringId, BytesId, NoneId)}, func(ctx *context, host OS, argument ...*Value) (*Value, error) {
+		values, collectError := plasma.collect(ctx, argument[0])
+		if collectError != nil {
+			return nil, collectError
+		}
+		argv := make([]string, 0, len(values))
+		for _, value := range values {
+			s, renderError := plasma.toString(ctx, value)
+			if renderError != nil {
+				return nil, renderError
+			}
+			argv = append(argv, s)
+		}
+		var stdin []byte
+		if len(argument) > 1 && argument[1].TypeId() != NoneId {
+			stdin = argument[1].Contents()
+		}
+		processResult, runError := host.Run(argv, stdin)
+		if runError != nil {
+			return nil, runError
+		}
+		result := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+		result.Set(magic_functions.Stdout, plasma.NewString(processResult.Stdout))
+		result.Set(magic_functions.Stderr, plasma.NewString(processResult.Stderr))
+		result.Set(magic_functions.Status, plasma.NewInt(int64(processResult.Status)))
+		return result, nil
+	})
+	return module
+}
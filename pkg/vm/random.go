@@ -0,0 +1,213 @@
+package vm
+
+import (
+	crypto_rand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+// randomSource serializes the access to a math/rand generator, which is not safe for concurrent use
+type randomSource struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+func newRandomSource(seed int64) *randomSource {
+	return &randomSource{rand: rand.New(rand.NewSource(seed))}
+}
+
+// cryptoSeed is used when neither the host nor the script provided a seed
+func cryptoSeed() int64 {
+	var seed [8]byte
+	if _, readError := crypto_rand.Read(seed[:]); readError != nil {
+		panic(readError)
+	}
+	return int64(binary.BigEndian.Uint64(seed[:]))
+}
+
+// SeedRandom resets the source used by the random module, making the scripts using it reproducible
+func (plasma *Plasma) SeedRandom(seed int64) {
+	plasma.random.mutex.Lock()
+	defer plasma.random.mutex.Unlock()
+	plasma.random.rand.Seed(seed)
+}
+
+// between returns an integer in [a, b], big integers are supported on both ends
+func (source *randomSource) between(a, b *big.Int) (*big.Int, error) {
+	if a.Cmp(b) > 0 {
+		return nil, fmt.Errorf("%w: %s > %s", InvalidRange, a, b)
+	}
+	span := new(big.Int).Sub(b, a)
+	span.Add(span, big.NewInt(1))
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	if span.IsInt64() {
+		return span.SetInt64(source.rand.Int63n(span.Int64())).Add(span, a), nil
+	}
+	return span.Rand(source.rand, span).Add(span, a), nil
+}
+
+func (source *randomSource) float() float64 {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	return source.rand.Float64()
+}
+
+func (source *randomSource) intn(n int) int {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	return source.rand.Intn(n)
+}
+
+func (source *randomSource) shuffle(values []*Value) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	source.rand.Shuffle(len(values), func(i, j int) {
+		values[i], values[j] = values[j], values[i]
+	})
+}
+
+func (source *randomSource) read(n int64) []byte {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	contents := make([]byte, n)
+	source.rand.Read(contents)
+	return contents
+}
+
+/*
+randomMethods:
+RandomInt		int
+RandomFloat		float
+Choice			choice
+Shuffle			shuffle
+Sample			sample
+int(a, b) includes both ends, sample(seq, k) picks k elements in random order without repeating positions
+*/
+func (plasma *Plasma) randomMethods(result *Value, source *randomSource) {
+	plasma.define(result.vtable, magic_functions.RandomInt, []Parameter{param("start", IntId), param("end", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if argument[0].TypeId() != IntId || argument[1].TypeId() != IntId {
+			return nil, NotOperable
+		}
+		i, rangeError := source.between(argument[0].GetBigInt(), argument[1].GetBigInt())
+		if rangeError != nil {
+			return nil, rangeError
+		}
+		return plasma.NewBigInt(i), nil
+	})
+	plasma.define(result.vtable, magic_functions.RandomFloat, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(source.float()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Choice, []Parameter{param("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		values, collectError := plasma.collect(ctx, argument[0])
+		if collectError != nil {
+			return nil, collectError
+		}
+		if len(values) == 0 {
+			return nil, EmptyIterable
+		}
+		return values[source.intn(len(values))], nil
+	})
+	plasma.define(result.vtable, magic_functions.Shuffle, []Parameter{param("array", ArrayId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if argument[0].TypeId() != ArrayId {
+			return nil, NotOperable
+		}
+		source.shuffle(argument[0].GetValues())
+		return plasma.none, nil
+	})
+	plasma.define(result.vtable, magic_functions.Sample, []Parameter{param("iterable"), param("k", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		values, collectError := plasma.collect(ctx, argument[0])
+		if collectError != nil {
+			return nil, collectError
+		}
+		// Big integers are compared as they are, truncating them could turn them into a valid size
+		k := argument[1].GetBigInt()
+		if k.Sign() < 0 || k.Cmp(big.NewInt(int64(len(values)))) > 0 {
+			return nil, fmt.Errorf("%w: sample of %s from %d values", InvalidRange, k, len(values))
+		}
+		// collect returns a fresh slice, so it can be shuffled in place
+		source.shuffle(values)
+		return plasma.NewArray(values[:k.Int64()]), nil
+	})
+}
+
+func (plasma *Plasma) randomClass() *Value {
+	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
+	class.SetAny(constructor(magic_functions.Random, []Parameter{optional("seed", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		seed := cryptoSeed()
+		if len(argument) > 0 {
+			if argument[0].TypeId() != IntId {
+				return nil, NotOperable
+			}
+			var seedError error
+			seed, seedError = argument[0].Int64()
+			if seedError != nil {
+				return nil, seedError
+			}
+		}
+		return plasma.newRandom(class, newRandomSource(seed)), nil
+	}))
+	return class
+}
+
+/*
+newRandom methods:
+Random methods	see randomMethods
+RandomBytes		bytes
+Every Random object owns its source, so objects created with the same seed produce the same values.
+*/
+func (plasma *Plasma) newRandom(class *Value, source *randomSource) *Value {
+	result := plasma.NewValue(plasma.rootSymbols, ValueId, class)
+	plasma.randomMethods(result, source)
+	plasma.define(result.vtable, magic_functions.RandomBytes, []Parameter{param("n", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n, countError := randomBytesCount(argument[0])
+		if countError != nil {
+			return nil, countError
+		}
+		return plasma.NewBytes(source.read(n)), nil
+	})
+	return result
+}
+
+// randomBytesCount validates the number of bytes requested, it is bounded like any other text by MaxTextLength
+func randomBytesCount(n *Value) (int64, error) {
+	count := n.GetBigInt()
+	if count.Sign() < 0 {
+		return 0, fmt.Errorf("%w: %s bytes", InvalidRange, count)
+	}
+	if count.Cmp(big.NewInt(MaxTextLength)) > 0 {
+		return 0, fmt.Errorf("%w: %s bytes are above %d", TextTooLong, count, MaxTextLength)
+	}
+	return count.Int64(), nil
+}
+
+/*
+randomModule functions:
+Random methods	see randomMethods
+RandomBytes		bytes
+Classes: Random
+The module functions share the VM source, which the host can seed with SeedRandom.
+bytes(n) always reads from crypto/rand and is not affected by the seed.
+*/
+func (plasma *Plasma) randomModule() *Value {
+	module := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.randomMethods(module, plasma.random)
+	module.Set(magic_functions.Random, plasma.randomClass())
+	plasma.define(module.vtable, magic_functions.RandomBytes, []Parameter{param("n", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n, countError := randomBytesCount(argument[0])
+		if countError != nil {
+			return nil, countError
+		}
+		contents := make([]byte, n)
+		if _, readError := crypto_rand.Read(contents); readError != nil {
+			return nil, readError
+		}
+		return plasma.NewBytes(contents), nil
+	})
+	return module
+}
@@ -0,0 +1,697 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+var NotConvertible = fmt.Errorf("not convertible")
+
+var (
+	valueType    = reflect.TypeOf((*Value)(nil))
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+type goVisit struct {
+	t       reflect.Type
+	pointer uintptr
+}
+
+/*
+converter translates values between Go and Plasma, the visiting sets detect cyclic structures
+on both directions. Shared, non cyclic, references are converted once per occurrence.
+*/
+type converter struct {
+	plasma     *Plasma
+	goVisiting map[goVisit]struct{}
+	visiting   map[*Value]struct{}
+}
+
+func (plasma *Plasma) newConverter() *converter {
+	return &converter{
+		plasma:     plasma,
+		goVisiting: map[goVisit]struct{}{},
+		visiting:   map[*Value]struct{}{},
+	}
+}
+
+/*
+ToValue converts a Go value to its Plasma equivalent:
+bool, integers and floats				Bool, Int and Float, uint64 above the int64 range become big integers
+string, []byte and [N]byte				String and Bytes
+slices and arrays						Array and Tuple
+maps									Hash, the keys are sorted when they are numbers, strings or bools
+structs									objects with an attribute per exported field, renamed with plasma:"name" or skipped with plasma:"-"
+pointers and interfaces					the value they point to, nil becomes none
+time.Time, time.Duration and *big.Int	DateTime, Duration and Int
+funcs									built-in functions converting their arguments and results, a trailing error result is raised
+*Value									itself
+*/
+func (plasma *Plasma) ToValue(v any) (*Value, error) {
+	return plasma.newConverter().toValue(reflect.ValueOf(v))
+}
+
+// ToGo stores the value in the variable target points to, converting it to its type like ToValue does the other way
+func (value *Value) ToGo(target any) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Pointer || pointer.IsNil() {
+		return fmt.Errorf("%w: target must be a non nil pointer, received %T", NotConvertible, target)
+	}
+	return value.plasma.newConverter().fromValue(value, pointer.Elem())
+}
+
+func (c *converter) enterGo(v reflect.Value) (func(), error) {
+	key := goVisit{t: v.Type(), pointer: v.Pointer()}
+	if _, found := c.goVisiting[key]; found {
+		return nil, fmt.Errorf("%w: %s", CyclicStructure, v.Type())
+	}
+	c.goVisiting[key] = struct{}{}
+	return func() { delete(c.goVisiting, key) }, nil
+}
+
+func (c *converter) enter(value *Value) (func(), error) {
+	if _, found := c.visiting[value]; found {
+		return nil, CyclicStructure
+	}
+	c.visiting[value] = struct{}{}
+	return func() { delete(c.visiting, value) }, nil
+}
+
+func (c *converter) toValue(v reflect.Value) (*Value, error) {
+	plasma := c.plasma
+	if !v.IsValid() {
+		return plasma.none, nil
+	}
+	switch v.Type() {
+	case valueType:
+		if v.IsNil() {
+			return plasma.none, nil
+		}
+		return v.Interface().(*Value), nil
+	case timeType:
+		return plasma.NewDateTime(v.Interface().(time.Time)), nil
+	case durationType:
+		return plasma.NewDuration(time.Duration(v.Int())), nil
+	case bigIntType:
+		if v.IsNil() {
+			return plasma.none, nil
+		}
+		return plasma.NewBigInt(new(big.Int).Set(v.Interface().(*big.Int))), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return plasma.NewBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return plasma.NewInt(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u > math.MaxInt64 {
+			return plasma.NewBigInt(new(big.Int).SetUint64(u)), nil
+		}
+		return plasma.NewInt(int64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return plasma.NewFloat(v.Float()), nil
+	case reflect.String:
+		return plasma.NewString([]byte(v.String())), nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return plasma.none, nil
+		}
+		return c.toValue(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return plasma.none, nil
+		}
+		leave, cyclicError := c.enterGo(v)
+		if cyclicError != nil {
+			return nil, cyclicError
+		}
+		defer leave()
+		return c.toValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return plasma.none, nil
+			}
+			if v.Len() > 0 {
+				leave, cyclicError := c.enterGo(v)
+				if cyclicError != nil {
+					return nil, cyclicError
+				}
+				defer leave()
+			}
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			contents := make([]byte, v.Len())
+			for index := range contents {
+				contents[index] = byte(v.Index(index).Uint())
+			}
+			return plasma.NewBytes(contents), nil
+		}
+		values := make([]*Value, 0, v.Len())
+		for index := 0; index < v.Len(); index++ {
+			element, elementError := c.toValue(v.Index(index))
+			if elementError != nil {
+				return nil, fmt.Errorf("index %d: %w", index, elementError)
+			}
+			values = append(values, element)
+		}
+		if v.Kind() == reflect.Array {
+			return plasma.NewTuple(values), nil
+		}
+		return plasma.NewArray(values), nil
+	case reflect.Map:
+		if v.IsNil() {
+			return plasma.none, nil
+		}
+		leave, cyclicError := c.enterGo(v)
+		if cyclicError != nil {
+			return nil, cyclicError
+		}
+		defer leave()
+		hash := plasma.NewInternalHash()
+		for _, key := range sortedMapKeys(v) {
+			k, keyError := c.toValue(key)
+			if keyError != nil {
+				return nil, keyError
+			}
+			element, elementError := c.toValue(v.MapIndex(key))
+			if elementError != nil {
+				return nil, fmt.Errorf("key %v: %w", key, elementError)
+			}
+			if setError := hash.Set(k, element); setError != nil {
+				return nil, setError
+			}
+		}
+		return plasma.NewHash(hash), nil
+	case reflect.Struct:
+		object := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+		for _, field := range structFields(v.Type()) {
+			element, elementError := c.toValue(v.FieldByIndex(field.index))
+			if elementError != nil {
+				return nil, fmt.Errorf("field %s: %w", field.name, elementError)
+			}
+			object.Set(field.name, element)
+		}
+		return object, nil
+	case reflect.Func:
+		if v.IsNil() {
+			return plasma.none, nil
+		}
+		return plasma.wrapFunc(v), nil
+	}
+	return nil, fmt.Errorf("%w: %s", NotConvertible, v.Type())
+}
+
+// sortedMapKeys makes the order of the converted hashes independent of the map iteration order
+func sortedMapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Kind() == reflect.Interface {
+			a, b = a.Elem(), b.Elem()
+			if !a.IsValid() || !b.IsValid() {
+				return !a.IsValid() && b.IsValid()
+			}
+			if a.Kind() != b.Kind() {
+				return a.Kind() < b.Kind()
+			}
+		}
+		switch a.Kind() {
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		}
+		return false
+	})
+	return keys
+}
+
+type structField struct {
+	name  string
+	index []int
+}
+
+// structFields lists the exported fields with their Plasma names, embedded structs without a name have their fields promoted
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		name, _, _ := strings.Cut(field.Tag.Get("plasma"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for _, promoted := range structFields(field.Type) {
+				promoted.index = append([]int{index}, promoted.index...)
+				fields = append(fields, promoted)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, structField{name: name, index: []int{index}})
+	}
+	return fields
+}
+
+/*
+wrapFunc exposes a Go function as a built-in, the arguments are converted to the parameter types
+and the results returned as none, a single value or a Tuple. A non nil trailing error is raised.
+The parameters of its signature are named after the Go types, since Go does not keep the names.
+*/
+func (plasma *Plasma) wrapFunc(function reflect.Value) *Value {
+	t := function.Type()
+	signature := Signature{Name: funcName(function)}
+	for index := 0; index < t.NumIn(); index++ {
+		if t.IsVariadic() && index == t.NumIn()-1 {
+			signature.Parameters = append(signature.Parameters, variadic(t.In(index).Elem().String()))
+			break
+		}
+		signature.Parameters = append(signature.Parameters, param(t.In(index).String()))
+	}
+	return plasma.NewBuiltInFunctionWithSignature(plasma.rootSymbols, signature,
+		func(argument ...*Value) (*Value, error) {
+			c := plasma.newConverter()
+			in, argumentsError := c.goArguments(t, argument)
+			if argumentsError != nil {
+				return nil, argumentsError
+			}
+			out := function.Call(in)
+			if n := len(out); n > 0 && t.Out(n-1) == errorType {
+				if !out[n-1].IsNil() {
+					return nil, out[n-1].Interface().(error)
+				}
+				out = out[:n-1]
+			}
+			switch len(out) {
+			case 0:
+				return plasma.none, nil
+			case 1:
+				return c.toValue(out[0])
+			}
+			values := make([]*Value, 0, len(out))
+			for _, result := range out {
+				value, convertError := c.toValue(result)
+				if convertError != nil {
+					return nil, convertError
+				}
+				values = append(values, value)
+			}
+			return plasma.NewTuple(values), nil
+		},
+	)
+}
+
+// funcName is the name of the Go function without its package, closures keep the generated one like func1
+func funcName(function reflect.Value) string {
+	name := "func"
+	if f := runtime.FuncForPC(function.Pointer()); f != nil {
+		name = f.Name()
+	}
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// goArguments expects the number of arguments already validated by the signature of wrapFunc
+func (c *converter) goArguments(t reflect.Type, argument []*Value) ([]reflect.Value, error) {
+	required := t.NumIn()
+	if t.IsVariadic() {
+		required--
+	}
+	in := make([]reflect.Value, 0, len(argument))
+	for index, value := range argument {
+		var parameter reflect.Type
+		if index >= required {
+			parameter = t.In(t.NumIn() - 1).Elem()
+		} else {
+			parameter = t.In(index)
+		}
+		target := reflect.New(parameter).Elem()
+		if convertError := c.fromValue(value, target); convertError != nil {
+			return nil, fmt.Errorf("argument %d: %w", index+1, convertError)
+		}
+		in = append(in, target)
+	}
+	return in, nil
+}
+
+func (c *converter) mismatch(value *Value, t reflect.Type) error {
+	return fmt.Errorf("%w: %s to %s", NotConvertible, value.TypeId(), t)
+}
+
+func (c *converter) fromValue(value *Value, target reflect.Value) error {
+	plasma := c.plasma
+	t := target.Type()
+	switch t {
+	case valueType:
+		target.Set(reflect.ValueOf(value))
+		return nil
+	case timeType:
+		if !plasma.isDateTime(value) {
+			return c.mismatch(value, t)
+		}
+		target.Set(reflect.ValueOf(value.getTime()))
+		return nil
+	case durationType:
+		if !plasma.isDuration(value) {
+			return c.mismatch(value, t)
+		}
+		target.SetInt(int64(value.getDuration()))
+		return nil
+	case bigIntType:
+		switch value.TypeId() {
+		case NoneId:
+			target.Set(reflect.Zero(t))
+		case IntId:
+			target.Set(reflect.ValueOf(new(big.Int).Set(value.GetBigInt())))
+		default:
+			return c.mismatch(value, t)
+		}
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		if value.TypeId() == NoneId {
+			target.Set(reflect.Zero(t))
+			return nil
+		}
+		natural, convertError := c.toAny(value)
+		if convertError != nil {
+			return convertError
+		}
+		if !reflect.TypeOf(natural).AssignableTo(t) {
+			return c.mismatch(value, t)
+		}
+		target.Set(reflect.ValueOf(natural))
+	case reflect.Pointer:
+		if value.TypeId() == NoneId {
+			target.Set(reflect.Zero(t))
+			return nil
+		}
+		element := reflect.New(t.Elem())
+		if convertError := c.fromValue(value, element.Elem()); convertError != nil {
+			return convertError
+		}
+		target.Set(element)
+	case reflect.Bool:
+		if value.TypeId() != BoolId {
+			return c.mismatch(value, t)
+		}
+		target.SetBool(value.GetBool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.TypeId() != IntId {
+			return c.mismatch(value, t)
+		}
+		i := value.GetBigInt()
+		if !i.IsInt64() || target.OverflowInt(i.Int64()) {
+			return fmt.Errorf("%w: %s overflows %s", NotConvertible, i, t)
+		}
+		target.SetInt(i.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.TypeId() != IntId {
+			return c.mismatch(value, t)
+		}
+		i := value.GetBigInt()
+		if !i.IsUint64() || target.OverflowUint(i.Uint64()) {
+			return fmt.Errorf("%w: %s overflows %s", NotConvertible, i, t)
+		}
+		target.SetUint(i.Uint64())
+	case reflect.Float32, reflect.Float64:
+		if value.TypeId() != IntId && value.TypeId() != FloatId {
+			return c.mismatch(value, t)
+		}
+		f := value.Float()
+		if target.OverflowFloat(f) {
+			return fmt.Errorf("%w: %v overflows %s", NotConvertible, f, t)
+		}
+		target.SetFloat(f)
+	case reflect.String:
+		if value.TypeId() != StringId && value.TypeId() != BytesId {
+			return c.mismatch(value, t)
+		}
+		target.SetString(string(value.GetBytes()))
+	case reflect.Slice, reflect.Array:
+		return c.fromSequence(value, target)
+	case reflect.Map:
+		return c.fromHash(value, target)
+	case reflect.Struct:
+		return c.fromObject(value, target)
+	case reflect.Func:
+		switch value.TypeId() {
+		case NoneId:
+			target.Set(reflect.Zero(t))
+		case BuiltInFunctionId, FunctionId, BuiltInClassId, ClassId:
+			target.Set(plasma.makeFunc(value, t))
+		default:
+			return c.mismatch(value, t)
+		}
+	default:
+		return c.mismatch(value, t)
+	}
+	return nil
+}
+
+func (c *converter) fromSequence(value *Value, target reflect.Value) error {
+	t := target.Type()
+	if t.Kind() == reflect.Slice && value.TypeId() == NoneId {
+		target.Set(reflect.Zero(t))
+		return nil
+	}
+	var elements []*Value
+	switch value.TypeId() {
+	case StringId, BytesId:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return c.mismatch(value, t)
+		}
+		contents := value.GetBytes()
+		elements = make([]*Value, 0, len(contents))
+		for _, b := range contents {
+			elements = append(elements, c.plasma.NewInt(int64(b)))
+		}
+	case ArrayId, TupleId:
+		elements = value.GetValues()
+	case SetId:
+		elements = value.GetHash().Keys()
+	default:
+		return c.mismatch(value, t)
+	}
+	leave, cyclicError := c.enter(value)
+	if cyclicError != nil {
+		return cyclicError
+	}
+	defer leave()
+	if t.Kind() == reflect.Slice {
+		target.Set(reflect.MakeSlice(t, len(elements), len(elements)))
+	} else if len(elements) != t.Len() {
+		return fmt.Errorf("%w: expecting %d elements but received %d", NotConvertible, t.Len(), len(elements))
+	}
+	for index, element := range elements {
+		if convertError := c.fromValue(element, target.Index(index)); convertError != nil {
+			return fmt.Errorf("index %d: %w", index, convertError)
+		}
+	}
+	return nil
+}
+
+func (c *converter) fromHash(value *Value, target reflect.Value) error {
+	t := target.Type()
+	switch value.TypeId() {
+	case NoneId:
+		target.Set(reflect.Zero(t))
+		return nil
+	case HashId:
+	default:
+		return c.mismatch(value, t)
+	}
+	leave, cyclicError := c.enter(value)
+	if cyclicError != nil {
+		return cyclicError
+	}
+	defer leave()
+	items := value.GetHash().Items()
+	m := reflect.MakeMapWithSize(t, len(items))
+	for _, item := range items {
+		key := reflect.New(t.Key()).Elem()
+		if convertError := c.fromValue(item.Key, key); convertError != nil {
+			return fmt.Errorf("key: %w", convertError)
+		}
+		if !key.Type().Comparable() || (key.Kind() == reflect.Interface && !key.IsNil() && !key.Elem().Type().Comparable()) {
+			return fmt.Errorf("%w: key of type %s", NotHashable, key.Type())
+		}
+		element := reflect.New(t.Elem()).Elem()
+		if convertError := c.fromValue(item.Value, element); convertError != nil {
+			return fmt.Errorf("key %v: %w", key, convertError)
+		}
+		m.SetMapIndex(key, element)
+	}
+	target.Set(m)
+	return nil
+}
+
+// fromObject fills the struct fields with the attributes of an object or the String keys of a Hash,
+// missing ones keep their current value
+func (c *converter) fromObject(value *Value, target reflect.Value) error {
+	t := target.Type()
+	var lookup func(name string) (*Value, bool, error)
+	switch value.TypeId() {
+	case ValueId:
+		lookup = func(name string) (*Value, bool, error) {
+			attribute, _, found := value.vtable.getLocal(name)
+			return attribute, found, nil
+		}
+	case HashId:
+		lookup = func(name string) (*Value, bool, error) {
+			key := c.plasma.NewString([]byte(name))
+			found, inError := value.GetHash().In(key)
+			if !found || inError != nil {
+				return nil, false, inError
+			}
+			element, getError := value.GetHash().Get(key)
+			return element, getError == nil, getError
+		}
+	default:
+		return c.mismatch(value, t)
+	}
+	leave, cyclicError := c.enter(value)
+	if cyclicError != nil {
+		return cyclicError
+	}
+	defer leave()
+	for _, field := range structFields(t) {
+		attribute, found, lookupError := lookup(field.name)
+		if lookupError != nil {
+			return lookupError
+		}
+		if !found {
+			continue
+		}
+		if convertError := c.fromValue(attribute, target.FieldByIndex(field.index)); convertError != nil {
+			return fmt.Errorf("field %s: %w", field.name, convertError)
+		}
+	}
+	return nil
+}
+
+/*
+toAny converts values stored in interfaces: Int goes to int64 or *big.Int, Float to float64,
+String to string, Bytes to []byte, Array, Tuple and Set to []any, Hash to map[any]any,
+DateTime and Duration to their time types. Anything else is kept as *Value.
+*/
+func (c *converter) toAny(value *Value) (any, error) {
+	plasma := c.plasma
+	switch {
+	case plasma.isDateTime(value):
+		return value.getTime(), nil
+	case plasma.isDuration(value):
+		return value.getDuration(), nil
+	}
+	var result any
+	switch value.TypeId() {
+	case BoolId:
+		result = value.GetBool()
+	case IntId:
+		if value.isBigInt() {
+			result = new(big.Int).Set(value.GetBigInt())
+		} else {
+			result = value.GetInt64()
+		}
+	case FloatId:
+		result = value.GetFloat64()
+	case StringId:
+		result = string(value.GetBytes())
+	case BytesId:
+		result = append([]byte{}, value.GetBytes()...)
+	case ArrayId, TupleId, SetId:
+		var elements []any
+		convertError := c.fromValue(value, reflect.ValueOf(&elements).Elem())
+		return elements, convertError
+	case HashId:
+		var elements map[any]any
+		convertError := c.fromValue(value, reflect.ValueOf(&elements).Elem())
+		return elements, convertError
+	default:
+		result = value
+	}
+	return result, nil
+}
+
+/*
+makeFunc builds a Go function of type t calling the Plasma callable. When the call or the conversion
+of its result fails the error goes to the trailing error result, functions without one panic.
+*/
+func (plasma *Plasma) makeFunc(function *Value, t reflect.Type) reflect.Value {
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		c := plasma.newConverter()
+		out := make([]reflect.Value, t.NumOut())
+		for index := range out {
+			out[index] = reflect.New(t.Out(index)).Elem()
+		}
+		results := out
+		returnsError := len(out) > 0 && t.Out(len(out)-1) == errorType
+		if returnsError {
+			results = out[:len(out)-1]
+		}
+		fail := func(err error) []reflect.Value {
+			if !returnsError {
+				panic(err)
+			}
+			out[len(out)-1].Set(reflect.ValueOf(&err).Elem())
+			return out
+		}
+		if t.IsVariadic() {
+			last := in[len(in)-1]
+			in = in[:len(in)-1]
+			for index := 0; index < last.Len(); index++ {
+				in = append(in, last.Index(index))
+			}
+		}
+		argument := make([]*Value, 0, len(in))
+		for index, value := range in {
+			converted, convertError := c.toValue(value)
+			if convertError != nil {
+				return fail(fmt.Errorf("argument %d: %w", index+1, convertError))
+			}
+			argument = append(argument, converted)
+		}
+		result, callError := plasma.CallFunction(function, argument...)
+		if callError != nil {
+			return fail(callError)
+		}
+		switch len(results) {
+		case 0:
+		case 1:
+			if convertError := c.fromValue(result, results[0]); convertError != nil {
+				return fail(convertError)
+			}
+		default:
+			if result.TypeId() != TupleId && result.TypeId() != ArrayId || len(result.GetValues()) != len(results) {
+				return fail(fmt.Errorf("%w: expecting %d results", NotConvertible, len(results)))
+			}
+			for index, element := range result.GetValues() {
+				if convertError := c.fromValue(element, results[index]); convertError != nil {
+					return fail(fmt.Errorf("result %d: %w", index+1, convertError))
+				}
+			}
+		}
+		return out
+	})
+}
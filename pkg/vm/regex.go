@@ -0,0 +1,223 @@
+package vm
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+var InvalidPattern = fmt.Errorf("invalid pattern")
+
+const regexCacheSize = 256
+
+// regexCache keeps the compiled patterns, it is emptied once it reaches regexCacheSize
+type regexCache struct {
+	mutex    sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+func (cache *regexCache) compile(pattern string) (*regexp.Regexp, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if compiled, found := cache.patterns[pattern]; found {
+		return compiled, nil
+	}
+	compiled, compileError := regexp.Compile(pattern)
+	if compileError != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidPattern, compileError.Error())
+	}
+	if len(cache.patterns) >= regexCacheSize {
+		cache.patterns = map[string]*regexp.Regexp{}
+	}
+	cache.patterns[pattern] = compiled
+	return compiled, nil
+}
+
+// textWrapper returns the constructor matching the type of the subject, strings are indexed by code point
+func (plasma *Plasma) textWrapper(subject *Value) (func([]byte) *Value, bool) {
+	if subject.TypeId() == BytesId {
+		return plasma.NewBytes, false
+	}
+	return plasma.NewString, true
+}
+
+/*
+newRegexMatch methods:
+Group	group
+Groups	groups
+Named	named
+Start	start
+Span	span
+*/
+func (plasma *Plasma) newRegexMatch(compiled *regexp.Regexp, subject *Value, indexes []int) *Value {
+	contents := subject.GetBytes()
+	wrap, runes := plasma.textWrapper(subject)
+	position := func(offset int) *Value {
+		if runes {
+			return plasma.NewInt(runeIndex(contents, offset))
+		}
+		return plasma.NewInt(int64(offset))
+	}
+	group := func(index int) *Value {
+		if indexes[2*index] < 0 {
+			return plasma.none
+		}
+		return wrap(contents[indexes[2*index]:indexes[2*index+1]])
+	}
+	match := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.define(match.vtable, magic_functions.Group, []Parameter{optional("group", IntId, StringId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if len(argument) == 0 {
+			return group(0), nil
+		}
+		index := -1
+		switch argument[0].TypeId() {
+		case IntId:
+			index = int(argument[0].Int())
+		case StringId:
+			index = compiled.SubexpIndex(argument[0].String())
+		}
+		if index < 0 || index > compiled.NumSubexp() {
+			return nil, NotIndexable
+		}
+		return group(index), nil
+	})
+	plasma.define(match.vtable, magic_functions.Groups, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		values := make([]*Value, 0, compiled.NumSubexp())
+		for index := 1; index <= compiled.NumSubexp(); index++ {
+			values = append(values, group(index))
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(match.vtable, magic_functions.Named, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		named := plasma.NewInternalHash()
+		for index, name := range compiled.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if setError := named.Set(plasma.NewString([]byte(name)), group(index)); setError != nil {
+				return nil, setError
+			}
+		}
+		return plasma.NewHash(named), nil
+	})
+	plasma.define(match.vtable, magic_functions.Start, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return position(indexes[0]), nil
+	})
+	// end is a keyword, so the end position is only exposed through span
+	plasma.define(match.vtable, magic_functions.Span, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewTuple([]*Value{position(indexes[0]), position(indexes[1])}), nil
+	})
+	plasma.define(match.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString(contents[indexes[0]:indexes[1]]), nil
+	})
+	return match
+}
+
+// regexReplace replaces up to n matches (all when n is negative), the replacement may be a template or a callback
+func (plasma *Plasma) regexReplace(ctx *context, compiled *regexp.Regexp, subject, replacement *Value, n int) (*Value, error) {
+	contents := subject.GetBytes()
+	wrap, _ := plasma.textWrapper(subject)
+	var result []byte
+	last := 0
+	for _, indexes := range compiled.FindAllSubmatchIndex(contents, n) {
+		result = append(result, contents[last:indexes[0]]...)
+		switch replacement.TypeId() {
+		case StringId, BytesId:
+			result = compiled.Expand(result, replacement.GetBytes(), contents, indexes)
+		default:
+			replaced, callError := plasma.call(ctx, replacement, plasma.newRegexMatch(compiled, subject, indexes))
+			if callError != nil {
+				return nil, callError
+			}
+			s, renderError := plasma.toString(ctx, replaced)
+			if renderError != nil {
+				return nil, renderError
+			}
+			result = append(result, s...)
+		}
+		last = indexes[1]
+	}
+	result = append(result, contents[last:]...)
+	return wrap(result), nil
+}
+
+/*
+newRegexPattern methods:
+Pattern		pattern
+Match		match
+Search		search
+FindAll		find_all
+Replace		replace
+Split		split
+*/
+func (plasma *Plasma) newRegexPattern(pattern string, compiled *regexp.Regexp) *Value {
+	result := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	result.Set(magic_functions.Pattern, plasma.NewString([]byte(pattern)))
+	plasma.define(result.vtable, magic_functions.Match, []Parameter{param("text", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		indexes := compiled.FindSubmatchIndex(argument[0].GetBytes())
+		if indexes == nil || indexes[0] != 0 {
+			// Leftmost-first semantics guarantee no other match starts at 0
+			return plasma.none, nil
+		}
+		return plasma.newRegexMatch(compiled, argument[0], indexes), nil
+	})
+	plasma.define(result.vtable, magic_functions.Search, []Parameter{param("text", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		indexes := compiled.FindSubmatchIndex(argument[0].GetBytes())
+		if indexes == nil {
+			return plasma.none, nil
+		}
+		return plasma.newRegexMatch(compiled, argument[0], indexes), nil
+	})
+	plasma.define(result.vtable, magic_functions.FindAll, []Parameter{param("text", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		all := compiled.FindAllSubmatchIndex(argument[0].GetBytes(), -1)
+		values := make([]*Value, 0, len(all))
+		for _, indexes := range all {
+			values = append(values, plasma.newRegexMatch(compiled, argument[0], indexes))
+		}
+		return plasma.NewArray(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Replace, []Parameter{param("text", textTypes...), param("replacement"), optional("count", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n := -1
+		if len(argument) > 2 {
+			n = int(argument[2].Int())
+		}
+		return plasma.regexReplace(ctx, compiled, argument[0], argument[1], n)
+	})
+	plasma.define(result.vtable, magic_functions.Split, []Parameter{param("text", textTypes...), optional("count", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n := -1
+		if len(argument) > 1 {
+			n = int(argument[1].Int())
+		}
+		wrap, _ := plasma.textWrapper(argument[0])
+		contents := argument[0].GetBytes()
+		var values []*Value
+		last := 0
+		for _, indexes := range compiled.FindAllIndex(contents, n) {
+			values = append(values, wrap(contents[last:indexes[0]]))
+			last = indexes[1]
+		}
+		values = append(values, wrap(contents[last:]))
+		return plasma.NewTuple(values), nil
+	})
+	return result
+}
+
+/*
+regexModule functions:
+Compile		compile
+*/
+func (plasma *Plasma) regexModule() *Value {
+	cache := &regexCache{patterns: map[string]*regexp.Regexp{}}
+	module := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.define(module.vtable, magic_functions.Compile, []Parameter{param("pattern", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		pattern := argument[0].String()
+		compiled, compileError := cache.compile(pattern)
+		if compileError != nil {
+			return nil, compileError
+		}
+		return plasma.newRegexPattern(pattern, compiled), nil
+	})
+	return module
+}
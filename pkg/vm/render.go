@@ -0,0 +1,201 @@
+package vm
+
+import (
+	"strconv"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+/*
+renderer builds the textual representation of values.
+Containers are rendered recursively quoting the strings they hold, a container found again while
+it is still being rendered is replaced by its placeholder ([...], (...), {...}) to break cycles.
+When plasma is available, objects are rendered through their __string__ or __repr__ methods,
+called as part of the execution in ctx.
+*/
+type renderer struct {
+	plasma   *Plasma
+	ctx      *context
+	visiting map[*Value]struct{}
+}
+
+// newRenderer returns the renderer of the script __string__ or __repr__ running in the execution,
+// so cycles passing through them are detected, or a new one for a top level render
+func (plasma *Plasma) newRenderer(ctx *context) *renderer {
+	if ctx == nil {
+		// Renders requested by the host are not part of any execution
+		ctx = &context{}
+	}
+	if ctx.renderer != nil {
+		return ctx.renderer
+	}
+	return &renderer{plasma: plasma, ctx: ctx}
+}
+
+func (r *renderer) enter(value *Value) bool {
+	if r.visiting == nil {
+		r.visiting = map[*Value]struct{}{}
+	}
+	if _, found := r.visiting[value]; found {
+		return false
+	}
+	r.visiting[value] = struct{}{}
+	return true
+}
+
+func (r *renderer) exit(value *Value) {
+	delete(r.visiting, value)
+}
+
+func (r *renderer) render(value *Value, quoted bool) (string, error) {
+	switch value.TypeId() {
+	case StringId:
+		if quoted {
+			return strconv.Quote(string(value.GetBytes())), nil
+		}
+		return string(value.GetBytes()), nil
+	case BytesId:
+		if quoted {
+			return "b" + strconv.Quote(string(value.GetBytes())), nil
+		}
+		return string(value.GetBytes()), nil
+	case ArrayId:
+		return r.renderSequence(value, "[", "]")
+	case TupleId:
+		return r.renderSequence(value, "(", ")")
+	case HashId:
+		return r.renderHash(value)
+	case SetId:
+		return r.renderSet(value)
+	case ValueId:
+		return r.renderObject(value, quoted)
+	}
+	return value.String(), nil
+}
+
+func (r *renderer) renderSequence(value *Value, open, close string) (string, error) {
+	if !r.enter(value) {
+		return open + "..." + close, nil
+	}
+	defer r.exit(value)
+	rawString := []byte(open)
+	for index, element := range value.GetValues() {
+		if index != 0 {
+			rawString = append(rawString, ',', ' ')
+		}
+		s, renderError := r.render(element, true)
+		if renderError != nil {
+			return "", renderError
+		}
+		rawString = append(rawString, s...)
+	}
+	if value.TypeId() == TupleId && len(value.GetValues()) == 1 {
+		rawString = append(rawString, ',')
+	}
+	rawString = append(rawString, close...)
+	return string(rawString), nil
+}
+
+func (r *renderer) renderHash(value *Value) (string, error) {
+	if !r.enter(value) {
+		return "{...}", nil
+	}
+	defer r.exit(value)
+	rawString := []byte{'{'}
+	for index, item := range value.GetHash().Items() {
+		if index != 0 {
+			rawString = append(rawString, ',', ' ')
+		}
+		key, renderError := r.render(item.Key, true)
+		if renderError != nil {
+			return "", renderError
+		}
+		rawString = append(rawString, key...)
+		rawString = append(rawString, ':', ' ')
+		s, renderError := r.render(item.Value, true)
+		if renderError != nil {
+			return "", renderError
+		}
+		rawString = append(rawString, s...)
+	}
+	rawString = append(rawString, '}')
+	return string(rawString), nil
+}
+
+// renderSet renders the elements between braces, the empty set is rendered as Set() so it is not confused with {}
+func (r *renderer) renderSet(value *Value) (string, error) {
+	keys := value.GetHash().Keys()
+	if len(keys) == 0 {
+		return "Set()", nil
+	}
+	if !r.enter(value) {
+		return "{...}", nil
+	}
+	defer r.exit(value)
+	rawString := []byte{'{'}
+	for index, key := range keys {
+		if index != 0 {
+			rawString = append(rawString, ',', ' ')
+		}
+		s, renderError := r.render(key, true)
+		if renderError != nil {
+			return "", renderError
+		}
+		rawString = append(rawString, s...)
+	}
+	rawString = append(rawString, '}')
+	return string(rawString), nil
+}
+
+// renderObject uses __repr__ inside containers and __string__ at the top level, falling back to the other one
+func (r *renderer) renderObject(value *Value, quoted bool) (string, error) {
+	if r.plasma == nil {
+		return value.String(), nil
+	}
+	methods := []string{magic_functions.String, magic_functions.Repr}
+	if quoted {
+		methods[0], methods[1] = methods[1], methods[0]
+	}
+	for _, method := range methods {
+		// On demand symbols are skipped on purpose, only script defined methods override the output
+		function, getError := value.vtable.Get(method)
+		if getError != nil || function.isOnDemand() {
+			continue
+		}
+		if !r.enter(value) {
+			return "...", nil
+		}
+		// The renders started by the script method reuse this renderer
+		previous := r.ctx.renderer
+		r.ctx.renderer = r
+		result, callError := r.plasma.call(r.ctx, function)
+		r.ctx.renderer = previous
+		r.exit(value)
+		if callError != nil {
+			return "", callError
+		}
+		if result.TypeId() == StringId || result.TypeId() == BytesId {
+			return string(result.GetBytes()), nil
+		}
+		return r.render(result, false)
+	}
+	return value.String(), nil
+}
+
+// ToString renders the value the way print and println do, strings are written without quotes
+func (plasma *Plasma) ToString(value *Value) (string, error) {
+	return plasma.toString(nil, value)
+}
+
+// Repr renders the value the way it is shown inside containers and by the REPL
+func (plasma *Plasma) Repr(value *Value) (string, error) {
+	return plasma.repr(nil, value)
+}
+
+func (plasma *Plasma) toString(ctx *context, value *Value) (string, error) {
+	return plasma.newRenderer(ctx).render(value, false)
+}
+
+func (plasma *Plasma) repr(ctx *context, value *Value) (string, error) {
+	return plasma.newRenderer(ctx).render(value, true)
+}
@@ -0,0 +1,98 @@
+package vm
+
+import (
+	gocontext "context"
+	"io"
+)
+
+/*
+NewScope creates the global symbols of an isolated execution. Its parent is the root of the VM,
+so the built-ins are shared without rebuilding them while the globals defined by the script stay
+in the scope. The built-ins are frozen, scripts assigning or deleting their attributes fail with
+FrozenValue. Host values only one execution should see are Set in its scope instead of using Load.
+*/
+func (plasma *Plasma) NewScope() *Symbols {
+	return NewSymbols(plasma.rootSymbols)
+}
+
+// ExecuteIn runs the bytecode with the scope as its global symbols
+func (plasma *Plasma) ExecuteIn(scope *Symbols, bytecode []byte) (result chan *Value, err chan error, stop chan struct{}) {
+	ctx := plasma.newContext(bytecode)
+	ctx.currentSymbols = scope
+	ctx.result = make(chan *Value, 1)
+	ctx.err = make(chan error, 1)
+	ctx.stop = make(chan struct{}, 1)
+	go plasma.executeCtx(ctx)
+	return ctx.result, ctx.err, ctx.stop
+}
+
+/*
+Pool keeps warmed VMs so concurrent executions can reuse them instead of creating the built-ins
+again. The VMs are created by the factory, where the host configures them (file system, os, clock).
+Execute runs every script in a new scope and restores the standard streams after it, the globals
+of an execution are never seen by the next one using the same VM. The state kept by the host
+side is not reset: the random generator continues its sequence, and the environment variables
+and files written through the OS and FileSystem of the VM stay. Hosts needing a clean state
+configure it in the setup of Execute, with SeedRandom, SetOS and SetFileSystem.
+*/
+type Pool struct {
+	factory func() *Plasma
+	vms     chan *Plasma
+}
+
+// NewPool creates a pool keeping up to size idle VMs
+func NewPool(size int, factory func() *Plasma) *Pool {
+	return &Pool{
+		factory: factory,
+		vms:     make(chan *Plasma, size),
+	}
+}
+
+// Get returns an idle VM or creates a new one when there is none
+func (pool *Pool) Get() *Plasma {
+	select {
+	case plasma := <-pool.vms:
+		return plasma
+	default:
+		return pool.factory()
+	}
+}
+
+// Put returns a VM once nothing runs on it, it is dropped when the pool is full
+func (pool *Pool) Put(plasma *Plasma) {
+	select {
+	case pool.vms <- plasma:
+	default:
+	}
+}
+
+/*
+Execute runs the bytecode in a new scope of a pooled VM and waits for it. Setup is called before
+the execution to fill the scope or to redirect the standard streams of the VM. When the context
+is done the execution is stopped and Execute returns the error of the context.
+*/
+func (pool *Pool) Execute(ctx gocontext.Context, bytecode []byte, setup func(plasma *Plasma, scope *Symbols)) (*Value, error) {
+	plasma := pool.Get()
+	defer pool.Put(plasma)
+	stdin, stdout, stderr := plasma.Stdin, plasma.Stdout, plasma.Stderr
+	defer plasma.restoreStreams(stdin, stdout, stderr)
+	scope := plasma.NewScope()
+	if setup != nil {
+		setup(plasma, scope)
+	}
+	result, err, stop := plasma.ExecuteIn(scope, bytecode)
+	select {
+	case executionError := <-err:
+		return <-result, executionError
+	case <-ctx.Done():
+		stop <- struct{}{}
+		// The VM goes back to the pool only once the execution ended
+		<-err
+		<-result
+		return nil, ctx.Err()
+	}
+}
+
+func (plasma *Plasma) restoreStreams(stdin io.Reader, stdout, stderr io.Writer) {
+	plasma.Stdin, plasma.Stdout, plasma.Stderr = stdin, stdout, stderr
+}
@@ -0,0 +1,240 @@
+package vm
+
+import (
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+)
+
+func (plasma *Plasma) setClass() *Value {
+	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
+	class.SetAny(constructor(special_symbols.Set, []Parameter{optional("iterable")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		set := plasma.NewInternalHash()
+		if len(argument) > 0 {
+			if !isIterable(argument[0]) {
+				return nil, &TypeError{
+					Function:  special_symbols.Set,
+					Parameter: "iterable",
+					Expected:  iterableTypes,
+					Received:  argument[0].TypeId(),
+				}
+			}
+			iterError := plasma.iterate(ctx, argument[0], func(value *Value) error {
+				return set.Set(value, plasma.none)
+			})
+			if iterError != nil {
+				return nil, iterError
+			}
+		}
+		return plasma.NewSet(set), nil
+	}))
+	return class
+}
+
+// setOperation applies the algebra operation to the elements of both sets, the result keeps the order of the left one
+func (plasma *Plasma) setOperation(ctx *context, set, other *Value, keep func(inSet, inOther bool) bool) (*Value, error) {
+	if other.TypeId() != SetId {
+		return nil, NotOperable
+	}
+	result := plasma.NewInternalHash()
+	add := func(key *Value, inSet, inOther bool) error {
+		if !keep(inSet, inOther) {
+			return nil
+		}
+		return result.set(ctx, key, plasma.none)
+	}
+	for _, key := range set.GetHash().Keys() {
+		inOther, inError := other.GetHash().contains(ctx, key)
+		if inError != nil {
+			return nil, inError
+		}
+		if addError := add(key, true, inOther); addError != nil {
+			return nil, addError
+		}
+	}
+	for _, key := range other.GetHash().Keys() {
+		inSet, inError := set.GetHash().contains(ctx, key)
+		if inError != nil {
+			return nil, inError
+		}
+		if inSet {
+			// Already considered while walking the left set
+			continue
+		}
+		if addError := add(key, false, true); addError != nil {
+			return nil, addError
+		}
+	}
+	return plasma.NewSet(result), nil
+}
+
+// isSubset reports if every element of set is also in other
+func isSubset(ctx *context, set, other *Hash) (bool, error) {
+	if set.Size() > other.Size() {
+		return false, nil
+	}
+	for _, key := range set.Keys() {
+		in, inError := other.contains(ctx, key)
+		if inError != nil || !in {
+			return false, inError
+		}
+	}
+	return true, nil
+}
+
+/*
+NewSet magic function:
+In                  __in__
+Equal               __equal__
+NotEqual            __not_equal__
+GreaterThan         __greater_than__
+GreaterOrEqualThan  __greater_or_equal_than__
+LessThan            __less_than__
+LessOrEqualThan     __less_or_equal_than__
+BitwiseOr           __bitwise_or__
+BitwiseAnd          __bitwise_and__
+BitwiseXor          __bitwise_xor__
+Sub                 __sub__
+Length              __len__
+Bool                __bool__
+String              __string__
+Copy                __copy__
+Iter                __iter__
+AddElement			add
+Remove				remove
+Discard				discard
+IsSubset			is_subset
+IsSuperset			is_superset
+IsDisjoint			is_disjoint
+The elements are the keys of the internal hash, the ordering operators are subset tests.
+*/
+func (plasma *Plasma) NewSet(set *Hash) *Value {
+	result := plasma.NewValue(plasma.rootSymbols, SetId, plasma.set)
+	result.SetAny(set)
+	plasma.define(result.vtable, magic_functions.In, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		in, inError := result.GetHash().contains(ctx, argument[0])
+		return plasma.NewBool(in), inError
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		equal, equalError := result.equals(ctx, argument[0], nil)
+		if equalError != nil {
+			return nil, equalError
+		}
+		return plasma.NewBool(equal), nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		equal, equalError := result.equals(ctx, argument[0], nil)
+		if equalError != nil {
+			return nil, equalError
+		}
+		return plasma.NewBool(!equal), nil
+	})
+	for name, subset := range map[string]func(ctx *context, set, other *Hash) (bool, error){
+		magic_functions.LessOrEqualThan: isSubset,
+		magic_functions.LessThan: func(ctx *context, set, other *Hash) (bool, error) {
+			subset, subsetError := isSubset(ctx, set, other)
+			return subset && set.Size() < other.Size(), subsetError
+		},
+		magic_functions.GreaterOrEqualThan: func(ctx *context, set, other *Hash) (bool, error) {
+			return isSubset(ctx, other, set)
+		},
+		magic_functions.GreaterThan: func(ctx *context, set, other *Hash) (bool, error) {
+			subset, subsetError := isSubset(ctx, other, set)
+			return subset && other.Size() < set.Size(), subsetError
+		},
+		magic_functions.IsSubset: isSubset,
+		magic_functions.IsSuperset: func(ctx *context, set, other *Hash) (bool, error) {
+			return isSubset(ctx, other, set)
+		},
+		magic_functions.IsDisjoint: func(ctx *context, set, other *Hash) (bool, error) {
+			for _, key := range set.Keys() {
+				in, inError := other.contains(ctx, key)
+				if inError != nil || in {
+					return false, inError
+				}
+			}
+			return true, nil
+		},
+	} {
+		subset := subset
+		plasma.define(result.vtable, name, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			if argument[0].TypeId() != SetId {
+				return nil, NotComparable
+			}
+			is, subsetError := subset(ctx, result.GetHash(), argument[0].GetHash())
+			if subsetError != nil {
+				return nil, subsetError
+			}
+			return plasma.NewBool(is), nil
+		})
+	}
+	for name, keep := range map[string]func(inSet, inOther bool) bool{
+		magic_functions.BitwiseOr: func(inSet, inOther bool) bool {
+			return true
+		},
+		magic_functions.BitwiseAnd: func(inSet, inOther bool) bool {
+			return inSet && inOther
+		},
+		magic_functions.BitwiseXor: func(inSet, inOther bool) bool {
+			return inSet != inOther
+		},
+		magic_functions.Sub: func(inSet, inOther bool) bool {
+			return inSet && !inOther
+		},
+	} {
+		keep := keep
+		plasma.define(result.vtable, name, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.setOperation(ctx, result, argument[0], keep)
+		})
+	}
+	plasma.define(result.vtable, magic_functions.Length, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(result.GetHash().Size()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result.Bool()), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s, renderError := plasma.repr(ctx, result)
+		if renderError != nil {
+			return nil, renderError
+		}
+		return plasma.NewString([]byte(s)), nil
+	})
+	plasma.define(result.vtable, magic_functions.Copy, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewSet(result.GetHash().Copy()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Iter, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		keys := result.GetHash().Keys()
+		iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
+		iter.SetAny(int64(0))
+		plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewBool(iter.GetInt64() < int64(len(keys))), nil
+		})
+		plasma.define(iter.vtable, magic_functions.Next, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			index := iter.GetInt64()
+			iter.SetAny(index + 1)
+			if index < int64(len(keys)) {
+				return keys[index], nil
+			}
+			return plasma.none, nil
+		})
+		return iter, nil
+	})
+	plasma.define(result.vtable, magic_functions.AddElement, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, result.GetHash().set(ctx, argument[0], plasma.none)
+	})
+	plasma.define(result.vtable, magic_functions.Remove, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		set := result.GetHash()
+		in, inError := set.contains(ctx, argument[0])
+		if inError != nil {
+			return nil, inError
+		}
+		if !in {
+			return nil, KeyNotFound
+		}
+		return plasma.none, set.del(ctx, argument[0])
+	})
+	plasma.define(result.vtable, magic_functions.Discard, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, result.GetHash().del(ctx, argument[0])
+	})
+	return result
+}
@@ -0,0 +1,216 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Parameter describes an argument of a built-in, when Types is empty any value is accepted
+	Parameter struct {
+		Name     string
+		Types    []TypeId
+		Optional bool
+		Variadic bool
+	}
+	/*
+		Signature is validated before the callback of a built-in runs, so callbacks can index the
+		required arguments and check only the optional ones with len(argument).
+		Optional parameters go after the required ones, a variadic one can only be the last.
+	*/
+	Signature struct {
+		Name       string
+		Parameters []Parameter
+	}
+	// ArgumentError reports a call with a number of arguments the signature does not accept
+	ArgumentError struct {
+		Signature Signature
+		Received  int
+	}
+	// TypeError reports an argument whose type is not in the ones accepted by its parameter
+	TypeError struct {
+		Function  string
+		Parameter string
+		Expected  []TypeId
+		Received  TypeId
+	}
+	builtIn struct {
+		signature Signature
+		callback  builtInCallback
+	}
+	// builtInCallback receives the context of the execution calling the built-in, nil when the host calls it,
+	// the script code it calls back through plasma.call honours the stop channel of that execution
+	builtInCallback func(ctx *context, argument ...*Value) (*Value, error)
+)
+
+var typeIdNames = [...]string{
+	ValueId:           "Value",
+	StringId:          "String",
+	BytesId:           "Bytes",
+	BoolId:            "Bool",
+	NoneId:            "None",
+	IntId:             "Int",
+	FloatId:           "Float",
+	ArrayId:           "Array",
+	TupleId:           "Tuple",
+	HashId:            "Hash",
+	BuiltInFunctionId: "Function",
+	FunctionId:        "Function",
+	BuiltInClassId:    "Class",
+	ClassId:           "Class",
+	SetId:             "Set",
+}
+
+func (id TypeId) String() string {
+	if id >= 0 && int(id) < len(typeIdNames) {
+		return typeIdNames[id]
+	}
+	return fmt.Sprintf("TypeId(%d)", int(id))
+}
+
+func (argumentError *ArgumentError) Error() string {
+	min, max := argumentError.Signature.bounds()
+	var expecting string
+	switch {
+	case max < 0:
+		expecting = fmt.Sprintf("at least %d", min)
+	case min == max:
+		expecting = fmt.Sprint(min)
+	default:
+		expecting = fmt.Sprintf("%d to %d", min, max)
+	}
+	return fmt.Sprintf("argument error: %s expects %s arguments but received %d",
+		argumentError.Signature.String(), expecting, argumentError.Received)
+}
+
+func (typeError *TypeError) Error() string {
+	expected := make([]string, 0, len(typeError.Expected))
+	for _, id := range typeError.Expected {
+		expected = append(expected, id.String())
+	}
+	return fmt.Sprintf("type error: argument %s of %s must be %s, received %s",
+		typeError.Parameter, typeError.Function, strings.Join(expected, " or "), typeError.Received)
+}
+
+// String renders the signature the way it is shown in the errors, like range(start, [stop], [step])
+func (signature *Signature) String() string {
+	parameters := make([]string, 0, len(signature.Parameters))
+	for _, parameter := range signature.Parameters {
+		switch {
+		case parameter.Variadic:
+			parameters = append(parameters, parameter.Name+"...")
+		case parameter.Optional:
+			parameters = append(parameters, "["+parameter.Name+"]")
+		default:
+			parameters = append(parameters, parameter.Name)
+		}
+	}
+	return signature.Name + "(" + strings.Join(parameters, ", ") + ")"
+}
+
+// bounds returns the minimum and maximum number of arguments, max is -1 for variadic signatures
+func (signature *Signature) bounds() (min, max int) {
+	for _, parameter := range signature.Parameters {
+		switch {
+		case parameter.Variadic:
+			return min, -1
+		case !parameter.Optional:
+			min++
+		}
+		max++
+	}
+	return min, max
+}
+
+// Check validates the number and the types of the arguments
+func (signature *Signature) Check(argument []*Value) error {
+	min, max := signature.bounds()
+	if len(argument) < min || (max >= 0 && len(argument) > max) {
+		return &ArgumentError{Signature: *signature, Received: len(argument)}
+	}
+	for index, value := range argument {
+		parameter := signature.Parameters[len(signature.Parameters)-1]
+		if index < len(signature.Parameters) {
+			parameter = signature.Parameters[index]
+		}
+		if len(parameter.Types) == 0 {
+			continue
+		}
+		accepted := false
+		for _, id := range parameter.Types {
+			if value.TypeId() == id {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return &TypeError{
+				Function:  signature.Name,
+				Parameter: parameter.Name,
+				Expected:  parameter.Types,
+				Received:  value.TypeId(),
+			}
+		}
+	}
+	return nil
+}
+
+func (b *builtIn) call(ctx *context, argument []*Value) (*Value, error) {
+	if checkError := b.signature.Check(argument); checkError != nil {
+		return nil, checkError
+	}
+	return b.callback(ctx, argument...)
+}
+
+// NewBuiltInFunctionWithSignature creates a built-in whose arguments are validated against the signature before calling it
+func (plasma *Plasma) NewBuiltInFunctionWithSignature(parent *Symbols, signature Signature, callback Callback) *Value {
+	return plasma.newBuiltIn(parent, signature, func(_ *context, argument ...*Value) (*Value, error) {
+		return callback(argument...)
+	})
+}
+
+func (plasma *Plasma) newBuiltIn(parent *Symbols, signature Signature, callback builtInCallback) *Value {
+	function := plasma.NewValue(parent, BuiltInFunctionId, plasma.function)
+	function.SetAny(&builtIn{signature: signature, callback: callback})
+	return function
+}
+
+// Signature returns the signature of built-in functions and classes created with one
+func (value *Value) Signature() (Signature, bool) {
+	b, ok := value.GetAny().(*builtIn)
+	if !ok {
+		return Signature{}, false
+	}
+	return b.signature, true
+}
+
+// define sets a validated built-in in the symbols, the name is also the one used by its signature
+func (plasma *Plasma) define(symbols *Symbols, name string, parameters []Parameter, callback builtInCallback) {
+	symbols.Set(name, plasma.newBuiltIn(symbols, Signature{Name: name, Parameters: parameters}, callback))
+}
+
+// constructor is the callback of built-in classes validated with the parameters
+func constructor(name string, parameters []Parameter, callback builtInCallback) *builtIn {
+	return &builtIn{signature: Signature{Name: name, Parameters: parameters}, callback: callback}
+}
+
+func param(name string, types ...TypeId) Parameter {
+	return Parameter{Name: name, Types: types}
+}
+
+func optional(name string, types ...TypeId) Parameter {
+	return Parameter{Name: name, Types: types, Optional: true}
+}
+
+func variadic(name string, types ...TypeId) Parameter {
+	return Parameter{Name: name, Types: types, Variadic: true}
+}
+
+// Parameters shared by most of the methods
+var (
+	noParameters    []Parameter
+	otherParameters = []Parameter{param("other")}
+	numberTypes     = []TypeId{IntId, FloatId, BoolId}
+	textTypes       = []TypeId{StringId, BytesId}
+	sequenceTypes   = []TypeId{ArrayId, TupleId}
+)
@@ -0,0 +1,160 @@
+package vm
+
+import (
+	"bytes"
+	"sort"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+// lessThan orders numbers, strings and bytes natively, any other value is asked through its __less_than__
+func (plasma *Plasma) lessThan(ctx *context, a, b *Value) (bool, error) {
+	aType, bType := a.TypeId(), b.TypeId()
+	switch {
+	case aType == IntId && bType == IntId:
+		return compareIntegers(a, b) < 0, nil
+	case (aType == IntId || aType == FloatId) && (bType == IntId || bType == FloatId):
+		return a.Float() < b.Float(), nil
+	case (aType == StringId || aType == BytesId) && aType == bType:
+		return bytes.Compare(a.GetBytes(), b.GetBytes()) < 0, nil
+	}
+	method, getError := a.Get(magic_functions.LessThan)
+	if getError != nil {
+		return false, NotComparable
+	}
+	result, callError := plasma.call(ctx, method, b)
+	if callError != nil {
+		return false, callError
+	}
+	return result.Bool(), nil
+}
+
+// sortKey returns the value the sort compares, the value itself when there is no key function
+func (plasma *Plasma) sortKey(ctx *context, value, key *Value) (*Value, error) {
+	if key == nil || key.TypeId() == NoneId {
+		return value, nil
+	}
+	return plasma.call(ctx, key, value)
+}
+
+// sortKeys returns the keys of every value, the values themselves when there is no key function
+func (plasma *Plasma) sortKeys(ctx *context, values []*Value, key *Value) ([]*Value, error) {
+	if key == nil || key.TypeId() == NoneId {
+		return values, nil
+	}
+	keys := make([]*Value, len(values))
+	for index, value := range values {
+		k, keyError := plasma.sortKey(ctx, value, key)
+		if keyError != nil {
+			return nil, keyError
+		}
+		keys[index] = k
+	}
+	return keys, nil
+}
+
+/*
+sortValues sorts in place with a stable sort, the key function is called once per value.
+Reversed sorts keep equal values in their original order too.
+*/
+func (plasma *Plasma) sortValues(ctx *context, values []*Value, key *Value, reverse bool) error {
+	keys, keysError := plasma.sortKeys(ctx, values, key)
+	if keysError != nil {
+		return keysError
+	}
+	indexes := make([]int, len(values))
+	for index := range indexes {
+		indexes[index] = index
+	}
+	var sortError error
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if sortError != nil {
+			return false
+		}
+		a, b := keys[indexes[i]], keys[indexes[j]]
+		if reverse {
+			a, b = b, a
+		}
+		less, lessError := plasma.lessThan(ctx, a, b)
+		if lessError != nil {
+			sortError = lessError
+			return false
+		}
+		return less
+	})
+	if sortError != nil {
+		return sortError
+	}
+	sorted := make([]*Value, len(values))
+	for position, index := range indexes {
+		sorted[position] = values[index]
+	}
+	copy(values, sorted)
+	return nil
+}
+
+// binarySearch returns the index of the value in the sorted values or -1 when it is not present,
+// the key function is only called for the probed values
+func (plasma *Plasma) binarySearch(ctx *context, values []*Value, target, key *Value) (int, error) {
+	low, high := 0, len(values)
+	for low < high {
+		middle := int(uint(low+high) >> 1)
+		k, keyError := plasma.sortKey(ctx, values[middle], key)
+		if keyError != nil {
+			return 0, keyError
+		}
+		less, lessError := plasma.lessThan(ctx, k, target)
+		if lessError != nil {
+			return 0, lessError
+		}
+		if less {
+			low = middle + 1
+		} else {
+			high = middle
+		}
+	}
+	if low < len(values) {
+		k, keyError := plasma.sortKey(ctx, values[low], key)
+		if keyError != nil {
+			return 0, keyError
+		}
+		equal, equalError := k.equals(ctx, target, nil)
+		if equalError != nil {
+			return 0, equalError
+		}
+		if equal {
+			return low, nil
+		}
+	}
+	return -1, nil
+}
+
+// extreme returns the minimum of the iterable, or its maximum when greatest is set
+func (plasma *Plasma) extreme(ctx *context, iterable, key *Value, greatest bool) (*Value, error) {
+	values, collectError := plasma.collect(ctx, iterable)
+	if collectError != nil {
+		return nil, collectError
+	}
+	if len(values) == 0 {
+		return nil, EmptyIterable
+	}
+	keys, keysError := plasma.sortKeys(ctx, values, key)
+	if keysError != nil {
+		return nil, keysError
+	}
+	best := 0
+	for index := 1; index < len(values); index++ {
+		a, b := keys[index], keys[best]
+		if greatest {
+			a, b = b, a
+		}
+		less, lessError := plasma.lessThan(ctx, a, b)
+		if lessError != nil {
+			return nil, lessError
+		}
+		if less {
+			best = index
+		}
+	}
+	return values[best], nil
+}
@@ -0,0 +1,183 @@
+package vm
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+/*
+streams owns the buffered reader of Plasma.Stdin. Every read of the VM goes through it, so bytes
+buffered by one call are still there for the next one. When the host replaces Stdin the buffer
+is dropped and a new reader is created.
+*/
+type streams struct {
+	mutex  sync.Mutex
+	source io.Reader
+	stdin  *bufio.Reader
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// StdinReader returns the buffered reader shared by input and stdin, hosts reading Stdin
+// while scripts run should use it too
+func (plasma *Plasma) StdinReader() *bufio.Reader {
+	plasma.streams.mutex.Lock()
+	defer plasma.streams.mutex.Unlock()
+	return plasma.stdinReader()
+}
+
+func (plasma *Plasma) stdinReader() *bufio.Reader {
+	if plasma.streams.stdin == nil || plasma.streams.source != plasma.Stdin {
+		source := plasma.Stdin
+		if source == nil {
+			source = eofReader{}
+		}
+		plasma.streams.source = plasma.Stdin
+		plasma.streams.stdin = bufio.NewReader(source)
+	}
+	return plasma.streams.stdin
+}
+
+type eofReader struct{}
+
+func (eofReader) Read(_ []byte) (int, error) {
+	return 0, io.EOF
+}
+
+// readLine reads the next line of Stdin, ok is false at the end of the input
+func (plasma *Plasma) readLine() (line []byte, ok bool, err error) {
+	plasma.streams.mutex.Lock()
+	defer plasma.streams.mutex.Unlock()
+	return readLine(plasma.stdinReader())
+}
+
+// write writes everything or fails, writers without a destination discard the contents
+func write(writer io.Writer, contents []byte) error {
+	if writer == nil {
+		return nil
+	}
+	_, writeError := writer.Write(contents)
+	return writeError
+}
+
+func flush(writer io.Writer) error {
+	if f, ok := writer.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+/*
+newInputStream methods:
+Read		read
+ReadLine	read_line
+ReadAll		read_all
+HasNext		__has_next__
+Next		__next__
+Iterating the stream yields its lines without the line terminator.
+*/
+func (plasma *Plasma) newInputStream() *Value {
+	result := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	method := func(name string, parameters []Parameter, callback func(reader *bufio.Reader, argument ...*Value) (*Value, error)) {
+		plasma.define(result.vtable, name, parameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			plasma.streams.mutex.Lock()
+			defer plasma.streams.mutex.Unlock()
+			return callback(plasma.stdinReader(), argument...)
+		})
+	}
+	method(magic_functions.Read, []Parameter{param("n", IntId)}, func(reader *bufio.Reader, argument ...*Value) (*Value, error) {
+		contents, readError := io.ReadAll(io.LimitReader(reader, argument[0].Int()))
+		if readError != nil {
+			return nil, readError
+		}
+		return plasma.NewString(contents), nil
+	})
+	method(magic_functions.ReadLine, noParameters, func(reader *bufio.Reader, argument ...*Value) (*Value, error) {
+		line, ok, readError := readLine(reader)
+		if readError != nil {
+			return nil, readError
+		}
+		if !ok {
+			return plasma.none, nil
+		}
+		return plasma.NewString(line), nil
+	})
+	method(magic_functions.ReadAll, noParameters, func(reader *bufio.Reader, argument ...*Value) (*Value, error) {
+		contents, readError := io.ReadAll(reader)
+		if readError != nil {
+			return nil, readError
+		}
+		return plasma.NewString(contents), nil
+	})
+	method(magic_functions.HasNext, noParameters, func(reader *bufio.Reader, argument ...*Value) (*Value, error) {
+		_, peekError := reader.Peek(1)
+		if peekError == io.EOF {
+			return plasma.false, nil
+		}
+		if peekError != nil {
+			return nil, peekError
+		}
+		return plasma.true, nil
+	})
+	method(magic_functions.Next, noParameters, func(reader *bufio.Reader, argument ...*Value) (*Value, error) {
+		line, _, readError := readLine(reader)
+		if readError != nil {
+			return nil, readError
+		}
+		return plasma.NewString(line), nil
+	})
+	return result
+}
+
+/*
+newOutputStream methods:
+Write		write
+Flush		flush
+The writer is looked up on every call, so replacing Plasma.Stdout or Plasma.Stderr takes effect
+immediately. Objects are written the way print renders them.
+*/
+func (plasma *Plasma) newOutputStream(writer func() io.Writer) *Value {
+	result := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	plasma.define(result.vtable, magic_functions.Write, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		var contents []byte
+		switch argument[0].TypeId() {
+		case StringId, BytesId:
+			contents = argument[0].GetBytes()
+		default:
+			s, renderError := plasma.toString(ctx, argument[0])
+			if renderError != nil {
+				return nil, renderError
+			}
+			contents = []byte(s)
+		}
+		if writeError := write(writer(), contents); writeError != nil {
+			return nil, writeError
+		}
+		return plasma.NewInt(int64(len(contents))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Flush, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.none, flush(writer())
+	})
+	return result
+}
+
+// printValues renders the values separated by spaces and writes them with a single call
+func (plasma *Plasma) printValues(ctx *context, values []*Value, end string) error {
+	var contents []byte
+	for index, value := range values {
+		if index != 0 {
+			contents = append(contents, ' ')
+		}
+		s, renderError := plasma.toString(ctx, value)
+		if renderError != nil {
+			return renderError
+		}
+		contents = append(contents, s...)
+	}
+	return write(plasma.Stdout, append(contents, end...))
+}
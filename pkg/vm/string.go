@@ -3,11 +3,13 @@ package vm
 import (
 	"bytes"
 	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
+	"unicode/utf8"
 )
 
 func (plasma *Plasma) stringClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.String, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewString(argument[0].Contents()), nil
 	}))
 	return class
@@ -35,206 +37,176 @@ Upper				upper
 Lower				lower
 Count				count
 Index				Index
+Encode				encode
+Text methods		see textMethods
 */
 func (plasma *Plasma) NewString(contents []byte) *Value {
 	result := plasma.NewValue(plasma.rootSymbols, StringId, plasma.string)
 	result.SetAny(contents)
-	result.Set(magic_functions.In, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case StringId:
-				return plasma.NewBool(bytes.Contains(result.GetBytes(), argument[0].GetBytes())), nil
-			case IntId:
-				i := argument[0].GetInt64()
-				for _, b := range result.GetBytes() {
-					if int64(b) == i {
-						return plasma.true, nil
-					}
-				}
+	plasma.define(result.vtable, magic_functions.In, []Parameter{param("value")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case StringId:
+			return plasma.NewBool(bytes.Contains(result.GetBytes(), argument[0].GetBytes())), nil
+		case IntId:
+			i, intError := argument[0].Int64()
+			if intError != nil {
 				return plasma.false, nil
 			}
-			return plasma.false, nil
-		},
-	))
-	result.Set(magic_functions.Equal, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(result.Equal(argument[0])), nil
-		},
-	))
-	result.Set(magic_functions.NotEqual, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(!result.Equal(argument[0])), nil
-		},
-	))
-	result.Set(magic_functions.Add, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case StringId:
-				s := result.GetBytes()
-				otherS := argument[0].GetBytes()
-				newString := make([]byte, 0, len(s)+len(otherS))
-				newString = append(newString, s...)
-				newString = append(newString, otherS...)
-				return plasma.NewString(newString), nil
-			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Mul, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId:
-				s := result.GetBytes()
-				times := argument[0].GetInt64()
-				return plasma.NewString(bytes.Repeat(s, int(times))), nil
+			for _, r := range string(result.GetBytes()) {
+				if int64(r) == i {
+					return plasma.true, nil
+				}
 			}
-			return nil, NotOperable
-		},
-	))
-	result.Set(magic_functions.Length, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewInt(int64(len(result.GetBytes()))), nil
-		},
-	))
-	result.Set(magic_functions.Bool, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBool(len(result.GetBytes()) > 0), nil
-		},
-	))
-	result.Set(magic_functions.String, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return result, nil
-		},
-	))
-	result.Set(magic_functions.Bytes, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewBytes(result.GetBytes()), nil
-		},
-	))
-	result.Set(magic_functions.Array, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
+			return plasma.false, nil
+		}
+		return plasma.false, nil
+	})
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(result.Equal(argument[0])), nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(!result.Equal(argument[0])), nil
+	})
+	plasma.define(result.vtable, magic_functions.Add, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case StringId:
 			s := result.GetBytes()
-			values := make([]*Value, 0, len(s))
-			for _, b := range s {
-				values = append(values, plasma.NewInt(int64(b)))
+			otherS := argument[0].GetBytes()
+			newString := make([]byte, 0, len(s)+len(otherS))
+			newString = append(newString, s...)
+			newString = append(newString, otherS...)
+			return plasma.NewString(newString), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Mul, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			repeated, repeatError := repeat(result.GetBytes(), argument[0])
+			if repeatError != nil {
+				return nil, repeatError
 			}
-			return plasma.NewArray(values), nil
-		},
-	))
-	result.Set(magic_functions.Tuple, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			s := result.GetBytes()
-			values := make([]*Value, 0, len(s))
-			for _, b := range s {
-				values = append(values, plasma.NewInt(int64(b)))
+			return plasma.NewString(repeated), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Length, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(int64(utf8.RuneCount(result.GetBytes()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Bool, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(len(result.GetBytes()) > 0), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Bytes, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBytes(result.GetBytes()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Array, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s := string(result.GetBytes())
+		values := make([]*Value, 0, len(s))
+		for _, r := range s {
+			values = append(values, plasma.NewInt(int64(r)))
+		}
+		return plasma.NewArray(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Tuple, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s := string(result.GetBytes())
+		values := make([]*Value, 0, len(s))
+		for _, r := range s {
+			values = append(values, plasma.NewInt(int64(r)))
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Get, []Parameter{param("index")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId:
+			index, intError := argument[0].Int64()
+			if intError != nil {
+				return nil, intError
 			}
-			return plasma.NewTuple(values), nil
-		},
-	))
-	result.Set(magic_functions.Get, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			switch argument[0].TypeId() {
-			case IntId:
-				s := result.GetBytes()
-				index := argument[0].GetInt64()
-				return plasma.NewInt(int64(s[index])), nil
-			case TupleId:
-				s := result.GetBytes()
-				values := argument[0].GetValues()
-				startIndex := values[0].GetInt64()
-				endIndex := values[1].GetInt64()
-				return plasma.NewString(s[startIndex:endIndex]), nil
+			s := result.GetBytes()
+			offset, valid := runeOffset(s, index)
+			if !valid || offset >= len(s) {
+				return nil, NotIndexable
 			}
-			return nil, NotIndexable
-		},
-	))
-	result.Set(magic_functions.Copy, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
+			_, size := utf8.DecodeRune(s[offset:])
+			return plasma.NewString(s[offset : offset+size]), nil
+		case TupleId:
 			s := result.GetBytes()
-			newS := make([]byte, len(s))
-			copy(newS, s)
-			return plasma.NewString(newS), nil
-		},
-	))
-	result.Set(magic_functions.Iter, plasma.NewBuiltInFunction(
-		result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
-			iter.SetAny(int64(0))
-			iter.Set(magic_functions.HasNext, plasma.NewBuiltInFunction(iter.vtable,
-				func(argument ...*Value) (*Value, error) {
-					return plasma.NewBool(iter.GetInt64() < int64(len(result.GetBytes()))), nil
-				},
-			))
-			iter.Set(magic_functions.Next, plasma.NewBuiltInFunction(iter.vtable,
-				func(argument ...*Value) (*Value, error) {
-					currentBytes := result.GetBytes()
-					index := iter.GetInt64()
-					iter.SetAny(index + 1)
-					if index < int64(len(currentBytes)) {
-						return plasma.NewString([]byte{currentBytes[index]}), nil
-					}
-					return plasma.none, nil
-				},
-			))
-			return iter, nil
-		},
-	))
-	result.Set(magic_functions.Join, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			values := argument[0].Values()
-			valuesBytes := make([][]byte, 0, len(values))
-			for _, value := range values {
-				valuesBytes = append(valuesBytes, []byte(value.String()))
+			values := argument[0].GetValues()
+			startOffset, startValid := runeOffset(s, values[0].GetInt64())
+			endOffset, endValid := runeOffset(s, values[1].GetInt64())
+			if !startValid || !endValid || startOffset > endOffset {
+				return nil, NotIndexable
 			}
-			return plasma.NewString(bytes.Join(valuesBytes, []byte(result.String()))), nil
-		},
-	))
-	result.Set(magic_functions.Split, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			sep := argument[0].String()
-			splitted := bytes.Split(result.GetBytes(), []byte(sep))
-			values := make([]*Value, 0, len(splitted))
-			for _, b := range splitted {
-				values = append(values, plasma.NewBytes(b))
+			return plasma.NewString(s[startOffset:endOffset]), nil
+		}
+		return nil, NotIndexable
+	})
+	plasma.define(result.vtable, magic_functions.Copy, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s := result.GetBytes()
+		newS := make([]byte, len(s))
+		copy(newS, s)
+		return plasma.NewString(newS), nil
+	})
+	plasma.define(result.vtable, magic_functions.Iter, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		iter := plasma.NewValue(result.vtable, ValueId, plasma.value)
+		iter.SetAny(int64(0))
+		plasma.define(iter.vtable, magic_functions.HasNext, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewBool(iter.GetInt64() < int64(len(result.GetBytes()))), nil
+		})
+		// The iterator keeps the byte offset of the next code point
+		plasma.define(iter.vtable, magic_functions.Next, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			currentBytes := result.GetBytes()
+			offset := iter.GetInt64()
+			if offset < int64(len(currentBytes)) {
+				_, size := utf8.DecodeRune(currentBytes[offset:])
+				iter.SetAny(offset + int64(size))
+				return plasma.NewString(currentBytes[offset : offset+int64(size)]), nil
 			}
-			return plasma.NewTuple(values), nil
-		},
-	))
-	result.Set(magic_functions.Upper, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewString(bytes.ToUpper(result.GetBytes())), nil
-		},
-	))
-	result.Set(magic_functions.Lower, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			return plasma.NewString(bytes.ToLower(result.GetBytes())), nil
-		},
-	))
-	result.Set(magic_functions.Count, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			sep := argument[0].String()
-			return plasma.NewInt(int64(bytes.Count(result.GetBytes(), []byte(sep)))), nil
-		},
-	))
-	result.Set(magic_functions.Index, plasma.NewBuiltInFunction(result.vtable,
-		func(argument ...*Value) (*Value, error) {
-			sep := argument[0].String()
-			return plasma.NewInt(int64(bytes.Index(result.GetBytes(), []byte(sep)))), nil
-		},
-	))
+			return plasma.none, nil
+		})
+		return iter, nil
+	})
+	plasma.define(result.vtable, magic_functions.Join, []Parameter{param("values", sequenceTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		values := argument[0].Values()
+		valuesBytes := make([][]byte, 0, len(values))
+		for _, value := range values {
+			valuesBytes = append(valuesBytes, []byte(value.String()))
+		}
+		return plasma.NewString(bytes.Join(valuesBytes, []byte(result.String()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Split, []Parameter{param("separator", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		sep := argument[0].String()
+		splitted := bytes.Split(result.GetBytes(), []byte(sep))
+		values := make([]*Value, 0, len(splitted))
+		for _, b := range splitted {
+			values = append(values, plasma.NewString(b))
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Upper, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString(bytes.ToUpper(result.GetBytes())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Lower, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString(bytes.ToLower(result.GetBytes())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Count, []Parameter{param("separator", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		sep := argument[0].String()
+		return plasma.NewInt(int64(bytes.Count(result.GetBytes(), []byte(sep)))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Index, []Parameter{param("separator", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		sep := argument[0].String()
+		s := result.GetBytes()
+		return plasma.NewInt(runeIndex(s, bytes.Index(s, []byte(sep)))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Encode, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		s := result.GetBytes()
+		encoded := make([]byte, len(s))
+		copy(encoded, s)
+		return plasma.NewBytes(encoded), nil
+	})
+	plasma.textMethods(result, plasma.NewString, true)
 	return result
 }
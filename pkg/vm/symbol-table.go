@@ -3,6 +3,7 @@ package vm
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -11,10 +12,11 @@ var (
 
 type (
 	Symbols struct {
-		mutex  *sync.Mutex
-		values map[string]*Value
-		call   *Symbols
-		Parent *Symbols
+		mutex   *sync.Mutex
+		values  map[string]*Value
+		version uint64
+		call    *Symbols
+		Parent  *Symbols
 	}
 )
 
@@ -31,6 +33,7 @@ func (symbols *Symbols) Set(name string, value *Value) {
 	symbols.mutex.Lock()
 	defer symbols.mutex.Unlock()
 	symbols.values[name] = value
+	atomic.AddUint64(&symbols.version, 1)
 }
 
 func (symbols *Symbols) Get(name string) (*Value, error) {
@@ -61,5 +64,26 @@ func (symbols *Symbols) Del(name string) error {
 		return SymbolNotFoundError
 	}
 	delete(symbols.values, name)
+	atomic.AddUint64(&symbols.version, 1)
 	return nil
 }
+
+// locals returns the values set in the table without looking in its parents
+func (symbols *Symbols) locals() []*Value {
+	symbols.mutex.Lock()
+	defer symbols.mutex.Unlock()
+	result := make([]*Value, 0, len(symbols.values))
+	for _, value := range symbols.values {
+		result = append(result, value)
+	}
+	return result
+}
+
+// getLocal only looks in the current table, returning also its version so the
+// result can be used as an inline cache entry
+func (symbols *Symbols) getLocal(name string) (*Value, uint64, bool) {
+	symbols.mutex.Lock()
+	defer symbols.mutex.Unlock()
+	value, found := symbols.values[name]
+	return value, atomic.LoadUint64(&symbols.version), found
+}
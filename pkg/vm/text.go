@@ -0,0 +1,367 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+var (
+	InvalidFormat = fmt.Errorf("invalid format")
+	InvalidUTF8   = fmt.Errorf("invalid utf-8")
+	InvalidFill   = fmt.Errorf("the fill must be a single character")
+	TextTooLong   = fmt.Errorf("text too long")
+	// MaxTextLength bounds the texts built by ljust, rjust, center, repeat and random bytes, so a single call can not exhaust the memory
+	MaxTextLength int64 = 1 << 30
+)
+
+// runeOffset converts an index counted in code points to a byte offset, indexes equal to the length are valid
+func runeOffset(s []byte, index int64) (int, bool) {
+	if index < 0 {
+		return 0, false
+	}
+	offset := 0
+	for ; index > 0; index-- {
+		if offset >= len(s) {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(s[offset:])
+		offset += size
+	}
+	return offset, true
+}
+
+// runeIndex converts a byte offset to an index counted in code points
+func runeIndex(s []byte, offset int) int64 {
+	if offset < 0 {
+		return int64(offset)
+	}
+	return int64(utf8.RuneCount(s[:offset]))
+}
+
+// validUTF8 returns InvalidUTF8 with the offset of the first invalid byte
+func validUTF8(s []byte) error {
+	for offset := 0; offset < len(s); {
+		r, size := utf8.DecodeRune(s[offset:])
+		if r == utf8.RuneError && size == 1 {
+			return fmt.Errorf("%w: invalid byte at offset %d", InvalidUTF8, offset)
+		}
+		offset += size
+	}
+	return nil
+}
+
+func allRunes(b []byte, predicate func(rune) bool) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, r := range string(b) {
+		if !predicate(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func title(b []byte) []byte {
+	result := make([]byte, 0, len(b))
+	previousIsLetter := false
+	for _, r := range string(b) {
+		if previousIsLetter {
+			r = unicode.ToLower(r)
+		} else {
+			r = unicode.ToTitle(r)
+		}
+		previousIsLetter = unicode.IsLetter(r)
+		result = utf8.AppendRune(result, r)
+	}
+	return result
+}
+
+func splitLines(b []byte) [][]byte {
+	var lines [][]byte
+	for len(b) > 0 {
+		index := bytes.IndexAny(b, "\r\n")
+		if index < 0 {
+			lines = append(lines, b)
+			break
+		}
+		lines = append(lines, b[:index])
+		if b[index] == '\r' && index+1 < len(b) && b[index+1] == '\n' {
+			index++
+		}
+		b = b[index+1:]
+	}
+	return lines
+}
+
+// justify pads the contents with the single character fill until width, left and right tell on which sides the padding goes
+func justify(contents []byte, width int64, fill []byte, left, right, runes bool) []byte {
+	missing := width - int64(len(contents))
+	if runes {
+		missing = width - int64(utf8.RuneCount(contents))
+	}
+	if missing <= 0 || len(fill) == 0 {
+		return contents
+	}
+	var before, after int64
+	switch {
+	case left && right:
+		before = missing / 2
+		after = missing - before
+	case left:
+		before = missing
+	default:
+		after = missing
+	}
+	result := make([]byte, 0, len(contents)+int(missing)*len(fill))
+	result = append(result, bytes.Repeat(fill, int(before))...)
+	result = append(result, contents...)
+	return append(result, bytes.Repeat(fill, int(after))...)
+}
+
+// repeat concatenates times copies of the contents, negative times produce an empty text
+func repeat(contents []byte, times *Value) ([]byte, error) {
+	n := times.GetBigInt()
+	if n.Sign() <= 0 {
+		return []byte{}, nil
+	}
+	length := new(big.Int).Mul(big.NewInt(int64(len(contents))), n)
+	if length.Cmp(big.NewInt(MaxTextLength)) > 0 {
+		return nil, fmt.Errorf("%w: %s bytes are above %d", TextTooLong, length, MaxTextLength)
+	}
+	return bytes.Repeat(contents, int(n.Int64())), nil
+}
+
+/*
+format replaces the placeholders of the template:
+{}		next positional argument
+{N}		positional argument N
+{name}	entry of the hash passed as the last argument
+{{ }}	literal braces
+*/
+func (plasma *Plasma) format(ctx *context, template []byte, argument []*Value) ([]byte, error) {
+	var (
+		result []byte
+		next   int
+		named  *Hash
+	)
+	if len(argument) > 0 && argument[len(argument)-1].TypeId() == HashId {
+		named = argument[len(argument)-1].GetHash()
+	}
+	for index := 0; index < len(template); index++ {
+		c := template[index]
+		switch {
+		case c == '{' && index+1 < len(template) && template[index+1] == '{',
+			c == '}' && index+1 < len(template) && template[index+1] == '}':
+			result = append(result, c)
+			index++
+			continue
+		case c == '}':
+			return nil, fmt.Errorf("%w: single '}' at %d", InvalidFormat, index)
+		case c != '{':
+			result = append(result, c)
+			continue
+		}
+		end := bytes.IndexByte(template[index:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed '{' at %d", InvalidFormat, index)
+		}
+		name := string(template[index+1 : index+end])
+		index += end
+		var value *Value
+		if position, parseError := strconv.Atoi(name); name == "" || parseError == nil {
+			if name == "" {
+				position = next
+				next++
+			}
+			if position < 0 || position >= len(argument) {
+				return nil, fmt.Errorf("%w: missing positional argument %d", InvalidFormat, position)
+			}
+			value = argument[position]
+		} else {
+			if named == nil {
+				return nil, fmt.Errorf("%w: missing named argument %s", InvalidFormat, name)
+			}
+			found, inError := named.In(plasma.NewString([]byte(name)))
+			if inError != nil {
+				return nil, inError
+			}
+			if !found {
+				return nil, fmt.Errorf("%w: missing named argument %s", InvalidFormat, name)
+			}
+			value, _ = named.Get(plasma.NewString([]byte(name)))
+		}
+		s, renderError := plasma.toString(ctx, value)
+		if renderError != nil {
+			return nil, renderError
+		}
+		result = append(result, s...)
+	}
+	return result, nil
+}
+
+/*
+textMethods installs the text processing methods shared by String and Bytes,
+wrap builds the result values with the same type of the receiver, when runes is set
+indexes and widths are counted in code points instead of bytes
+Strip				strip
+LeftStrip			lstrip
+RightStrip			rstrip
+Replace				replace
+StartsWith			starts_with
+EndsWith			ends_with
+Find				find
+RightFind			rfind
+SplitLines			split_lines
+Partition			partition
+LeftJust			ljust
+RightJust			rjust
+Center				center
+Repeat				repeat
+Title				title
+IsDigit				is_digit
+IsAlpha				is_alpha
+IsSpace				is_space
+Format				format
+*/
+func (plasma *Plasma) textMethods(result *Value, wrap func([]byte) *Value, runes bool) {
+	plasma.define(result.vtable, magic_functions.Strip, []Parameter{optional("characters", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if len(argument) > 0 {
+			return wrap(bytes.Trim(result.GetBytes(), argument[0].String())), nil
+		}
+		return wrap(bytes.TrimSpace(result.GetBytes())), nil
+	})
+	plasma.define(result.vtable, magic_functions.LeftStrip, []Parameter{optional("characters", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if len(argument) > 0 {
+			return wrap(bytes.TrimLeft(result.GetBytes(), argument[0].String())), nil
+		}
+		return wrap(bytes.TrimLeftFunc(result.GetBytes(), unicode.IsSpace)), nil
+	})
+	plasma.define(result.vtable, magic_functions.RightStrip, []Parameter{optional("characters", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		if len(argument) > 0 {
+			return wrap(bytes.TrimRight(result.GetBytes(), argument[0].String())), nil
+		}
+		return wrap(bytes.TrimRightFunc(result.GetBytes(), unicode.IsSpace)), nil
+	})
+	plasma.define(result.vtable, magic_functions.Replace, []Parameter{param("old", textTypes...), param("new", textTypes...), optional("count", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		n := -1
+		if len(argument) > 2 {
+			n = int(argument[2].Int())
+		}
+		return wrap(bytes.Replace(result.GetBytes(), []byte(argument[0].String()), []byte(argument[1].String()), n)), nil
+	})
+	plasma.define(result.vtable, magic_functions.StartsWith, []Parameter{param("prefix", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(bytes.HasPrefix(result.GetBytes(), []byte(argument[0].String()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.EndsWith, []Parameter{param("suffix", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(bytes.HasSuffix(result.GetBytes(), []byte(argument[0].String()))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Find, []Parameter{param("value", textTypes...), optional("start", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		contents := result.GetBytes()
+		start := 0
+		if len(argument) > 1 {
+			var valid bool
+			if runes {
+				start, valid = runeOffset(contents, argument[1].Int())
+			} else {
+				start, valid = int(argument[1].Int()), argument[1].Int() >= 0 && argument[1].Int() <= int64(len(contents))
+			}
+			if !valid {
+				return plasma.NewInt(-1), nil
+			}
+		}
+		index := bytes.Index(contents[start:], []byte(argument[0].String()))
+		if index < 0 {
+			return plasma.NewInt(-1), nil
+		}
+		if runes {
+			return plasma.NewInt(runeIndex(contents, start+index)), nil
+		}
+		return plasma.NewInt(int64(start + index)), nil
+	})
+	plasma.define(result.vtable, magic_functions.RightFind, []Parameter{param("value", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		contents := result.GetBytes()
+		index := bytes.LastIndex(contents, []byte(argument[0].String()))
+		if runes {
+			return plasma.NewInt(runeIndex(contents, index)), nil
+		}
+		return plasma.NewInt(int64(index)), nil
+	})
+	plasma.define(result.vtable, magic_functions.SplitLines, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		lines := splitLines(result.GetBytes())
+		values := make([]*Value, 0, len(lines))
+		for _, line := range lines {
+			values = append(values, wrap(line))
+		}
+		return plasma.NewTuple(values), nil
+	})
+	plasma.define(result.vtable, magic_functions.Partition, []Parameter{param("separator", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		contents := result.GetBytes()
+		sep := []byte(argument[0].String())
+		index := bytes.Index(contents, sep)
+		if index < 0 {
+			return plasma.NewTuple([]*Value{wrap(contents), wrap(nil), wrap(nil)}), nil
+		}
+		return plasma.NewTuple([]*Value{
+			wrap(contents[:index]),
+			wrap(sep),
+			wrap(contents[index+len(sep):]),
+		}), nil
+	})
+	for name, sides := range map[string][2]bool{
+		magic_functions.LeftJust:  {false, true},
+		magic_functions.RightJust: {true, false},
+		magic_functions.Center:    {true, true},
+	} {
+		left, right := sides[0], sides[1]
+		plasma.define(result.vtable, name, []Parameter{param("width", IntId), optional("fill", textTypes...)}, func(ctx *context, argument ...*Value) (*Value, error) {
+			width := argument[0].GetBigInt()
+			if width.Cmp(big.NewInt(MaxTextLength)) > 0 {
+				return nil, fmt.Errorf("%w: width %s is above %d", TextTooLong, width, MaxTextLength)
+			}
+			fill := []byte(" ")
+			if len(argument) > 1 {
+				fill = argument[1].GetBytes()
+				if (runes && utf8.RuneCount(fill) != 1) || (!runes && len(fill) != 1) {
+					return nil, InvalidFill
+				}
+			}
+			if width.Sign() < 0 {
+				return wrap(result.GetBytes()), nil
+			}
+			return wrap(justify(result.GetBytes(), width.Int64(), fill, left, right, runes)), nil
+		})
+	}
+	plasma.define(result.vtable, magic_functions.Repeat, []Parameter{param("times", IntId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		repeated, repeatError := repeat(result.GetBytes(), argument[0])
+		if repeatError != nil {
+			return nil, repeatError
+		}
+		return wrap(repeated), nil
+	})
+	plasma.define(result.vtable, magic_functions.Title, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return wrap(title(result.GetBytes())), nil
+	})
+	plasma.define(result.vtable, magic_functions.IsDigit, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(allRunes(result.GetBytes(), unicode.IsDigit)), nil
+	})
+	plasma.define(result.vtable, magic_functions.IsAlpha, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(allRunes(result.GetBytes(), unicode.IsLetter)), nil
+	})
+	plasma.define(result.vtable, magic_functions.IsSpace, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(allRunes(result.GetBytes(), unicode.IsSpace)), nil
+	})
+	plasma.define(result.vtable, magic_functions.Format, []Parameter{variadic("values")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		formatted, formatError := plasma.format(ctx, result.GetBytes(), argument)
+		if formatError != nil {
+			return nil, formatError
+		}
+		return wrap(formatted), nil
+	})
+}
@@ -0,0 +1,302 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	InvalidTimeFormat = fmt.Errorf("invalid time format")
+	InvalidDate       = fmt.Errorf("invalid date")
+	UnknownTimeZone   = fmt.Errorf("unknown time zone")
+)
+
+func padNumber(n, width int) string {
+	s := strconv.Itoa(n)
+	for len(s) < width {
+		s = "0" + s
+	}
+	return s
+}
+
+/*
+strftime renders t using the strftime directives:
+%Y year, %y two digits year, %m month, %d day, %j day of the year,
+%H hour (24h), %I hour (12h), %p AM/PM, %M minute, %S second, %f microseconds,
+%b and %B month name, %a and %A weekday name, %z UTC offset, %Z zone abbreviation
+and %% for a literal percent sign
+*/
+func strftime(t time.Time, layout string) (string, error) {
+	var builder strings.Builder
+	for index := 0; index < len(layout); index++ {
+		if layout[index] != '%' {
+			builder.WriteByte(layout[index])
+			continue
+		}
+		index++
+		if index == len(layout) {
+			return "", fmt.Errorf("%w: trailing %%", InvalidTimeFormat)
+		}
+		switch layout[index] {
+		case 'Y':
+			builder.WriteString(padNumber(t.Year(), 4))
+		case 'y':
+			builder.WriteString(padNumber(t.Year()%100, 2))
+		case 'm':
+			builder.WriteString(padNumber(int(t.Month()), 2))
+		case 'd':
+			builder.WriteString(padNumber(t.Day(), 2))
+		case 'j':
+			builder.WriteString(padNumber(t.YearDay(), 3))
+		case 'H':
+			builder.WriteString(padNumber(t.Hour(), 2))
+		case 'I':
+			hour := t.Hour() % 12
+			if hour == 0 {
+				hour = 12
+			}
+			builder.WriteString(padNumber(hour, 2))
+		case 'p':
+			if t.Hour() < 12 {
+				builder.WriteString("AM")
+			} else {
+				builder.WriteString("PM")
+			}
+		case 'M':
+			builder.WriteString(padNumber(t.Minute(), 2))
+		case 'S':
+			builder.WriteString(padNumber(t.Second(), 2))
+		case 'f':
+			builder.WriteString(padNumber(t.Nanosecond()/1000, 6))
+		case 'b':
+			builder.WriteString(t.Month().String()[:3])
+		case 'B':
+			builder.WriteString(t.Month().String())
+		case 'a':
+			builder.WriteString(t.Weekday().String()[:3])
+		case 'A':
+			builder.WriteString(t.Weekday().String())
+		case 'z':
+			builder.WriteString(t.Format("-0700"))
+		case 'Z':
+			name, _ := t.Zone()
+			builder.WriteString(name)
+		case '%':
+			builder.WriteByte('%')
+		default:
+			return "", fmt.Errorf("%w: unknown directive %%%c", InvalidTimeFormat, layout[index])
+		}
+	}
+	return builder.String(), nil
+}
+
+// timeParser consumes the text while strptime walks the layout
+type timeParser struct {
+	text   string
+	offset int
+}
+
+func (parser *timeParser) number(minDigits, maxDigits int) (int, int, error) {
+	start := parser.offset
+	for parser.offset < len(parser.text) && parser.offset-start < maxDigits &&
+		'0' <= parser.text[parser.offset] && parser.text[parser.offset] <= '9' {
+		parser.offset++
+	}
+	if parser.offset-start < minDigits {
+		return 0, 0, fmt.Errorf("%w: expecting number at offset %d", InvalidTimeFormat, start)
+	}
+	n, _ := strconv.Atoi(parser.text[start:parser.offset])
+	return n, parser.offset - start, nil
+}
+
+// name matches one of the candidates ignoring case, returning its index
+func (parser *timeParser) name(candidates []string) (int, error) {
+	rest := strings.ToLower(parser.text[parser.offset:])
+	for index, candidate := range candidates {
+		if strings.HasPrefix(rest, strings.ToLower(candidate)) {
+			parser.offset += len(candidate)
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown name at offset %d", InvalidTimeFormat, parser.offset)
+}
+
+func (parser *timeParser) offsetZone() (*time.Location, error) {
+	if parser.offset < len(parser.text) && parser.text[parser.offset] == 'Z' {
+		parser.offset++
+		return time.UTC, nil
+	}
+	if parser.offset == len(parser.text) || (parser.text[parser.offset] != '+' && parser.text[parser.offset] != '-') {
+		return nil, fmt.Errorf("%w: expecting utc offset at offset %d", InvalidTimeFormat, parser.offset)
+	}
+	sign := 1
+	if parser.text[parser.offset] == '-' {
+		sign = -1
+	}
+	parser.offset++
+	hours, _, hoursError := parser.number(2, 2)
+	if hoursError != nil {
+		return nil, hoursError
+	}
+	if parser.offset < len(parser.text) && parser.text[parser.offset] == ':' {
+		parser.offset++
+	}
+	minutes, _, minutesError := parser.number(2, 2)
+	if minutesError != nil {
+		return nil, minutesError
+	}
+	return time.FixedZone("", sign*(hours*3600+minutes*60)), nil
+}
+
+func (parser *timeParser) namedZone() (*time.Location, error) {
+	start := parser.offset
+	for parser.offset < len(parser.text) {
+		c := parser.text[parser.offset]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '/' || c == '_') {
+			break
+		}
+		parser.offset++
+	}
+	return loadZone(parser.text[start:parser.offset])
+}
+
+func loadZone(name string) (*time.Location, error) {
+	switch name {
+	case "UTC", "GMT", "Z":
+		return time.UTC, nil
+	}
+	location, loadError := time.LoadLocation(name)
+	if loadError != nil || name == "" {
+		return nil, fmt.Errorf("%w: %q", UnknownTimeZone, name)
+	}
+	return location, nil
+}
+
+var (
+	monthNames   = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	monthAbbrevs = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	dayNames     = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	dayAbbrevs   = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+)
+
+/*
+strptime parses text with the same directives accepted by strftime.
+The zone of the result is the one found in the text (%z or %Z), location otherwise.
+Dates that do not exist, like February 30, are rejected instead of normalized.
+*/
+func strptime(text, layout string, location *time.Location) (time.Time, error) {
+	parser := &timeParser{text: text}
+	year, month, day, yearDay := 1900, 1, 1, 0
+	hour, minute, second, nanosecond := 0, 0, 0, 0
+	pm, twelveHours := -1, false
+	for index := 0; index < len(layout); index++ {
+		if layout[index] != '%' {
+			if parser.offset == len(text) || text[parser.offset] != layout[index] {
+				return time.Time{}, fmt.Errorf("%w: expecting %q at offset %d", InvalidTimeFormat, layout[index], parser.offset)
+			}
+			parser.offset++
+			continue
+		}
+		index++
+		if index == len(layout) {
+			return time.Time{}, fmt.Errorf("%w: trailing %%", InvalidTimeFormat)
+		}
+		var parseError error
+		switch layout[index] {
+		case 'Y':
+			year, _, parseError = parser.number(4, 4)
+		case 'y':
+			year, _, parseError = parser.number(2, 2)
+			if year < 69 {
+				year += 2000
+			} else {
+				year += 1900
+			}
+		case 'm':
+			month, _, parseError = parser.number(1, 2)
+		case 'd':
+			day, _, parseError = parser.number(1, 2)
+		case 'j':
+			yearDay, _, parseError = parser.number(1, 3)
+			if parseError == nil && yearDay == 0 {
+				parseError = fmt.Errorf("%w: day of the year out of range", InvalidDate)
+			}
+		case 'H':
+			hour, _, parseError = parser.number(1, 2)
+		case 'I':
+			hour, _, parseError = parser.number(1, 2)
+			twelveHours = true
+			if parseError == nil && (hour < 1 || hour > 12) {
+				parseError = fmt.Errorf("%w: hour out of range", InvalidDate)
+			}
+		case 'p':
+			pm, parseError = parser.name([]string{"AM", "PM"})
+		case 'M':
+			minute, _, parseError = parser.number(1, 2)
+		case 'S':
+			second, _, parseError = parser.number(1, 2)
+		case 'f':
+			var digits int
+			nanosecond, digits, parseError = parser.number(1, 9)
+			for ; digits < 9; digits++ {
+				nanosecond *= 10
+			}
+		case 'b':
+			month, parseError = parser.name(monthAbbrevs)
+			month++
+		case 'B':
+			month, parseError = parser.name(monthNames)
+			month++
+		case 'a':
+			_, parseError = parser.name(dayAbbrevs)
+		case 'A':
+			_, parseError = parser.name(dayNames)
+		case 'z':
+			location, parseError = parser.offsetZone()
+		case 'Z':
+			location, parseError = parser.namedZone()
+		case '%':
+			if parser.offset == len(text) || text[parser.offset] != '%' {
+				parseError = fmt.Errorf("%w: expecting '%%' at offset %d", InvalidTimeFormat, parser.offset)
+			}
+			parser.offset++
+		default:
+			parseError = fmt.Errorf("%w: unknown directive %%%c", InvalidTimeFormat, layout[index])
+		}
+		if parseError != nil {
+			return time.Time{}, parseError
+		}
+	}
+	if parser.offset != len(text) {
+		return time.Time{}, fmt.Errorf("%w: unconverted text %q", InvalidTimeFormat, text[parser.offset:])
+	}
+	if twelveHours {
+		hour %= 12
+	}
+	if pm == 1 {
+		hour += 12
+	}
+	if yearDay != 0 {
+		t := time.Date(year, time.January, yearDay, 0, 0, 0, 0, time.UTC)
+		if t.Year() != year {
+			return time.Time{}, fmt.Errorf("%w: day of the year out of range", InvalidDate)
+		}
+		month, day = int(t.Month()), t.Day()
+	}
+	return makeDate(year, month, day, hour, minute, second, nanosecond, location)
+}
+
+// makeDate builds the time rejecting out of range fields, time.Date would normalize them
+func makeDate(year, month, day, hour, minute, second, nanosecond int, location *time.Location) (time.Time, error) {
+	if month < 1 || month > 12 || hour < 0 || hour > 23 || minute < 0 || minute > 59 ||
+		second < 0 || second > 59 || nanosecond < 0 || nanosecond > 999999999 {
+		return time.Time{}, InvalidDate
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, second, nanosecond, location)
+	if t.Day() != day || int(t.Month()) != month {
+		return time.Time{}, InvalidDate
+	}
+	return t, nil
+}
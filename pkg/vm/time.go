@@ -0,0 +1,409 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+	"time"
+	_ "time/tzdata"
+
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+)
+
+var DurationOutOfRange = fmt.Errorf("duration out of range")
+
+func (plasma *Plasma) isDateTime(value *Value) bool {
+	return value.TypeId() == ValueId && value.GetClass() == plasma.dateTime
+}
+
+func (plasma *Plasma) isDuration(value *Value) bool {
+	return value.TypeId() == ValueId && value.GetClass() == plasma.duration
+}
+
+func (value *Value) getTime() time.Time {
+	return value.GetAny().(time.Time)
+}
+
+func (value *Value) getDuration() time.Duration {
+	return value.GetAny().(time.Duration)
+}
+
+// toDuration accepts durations and numbers of seconds
+func (plasma *Plasma) toDuration(value *Value) (time.Duration, error) {
+	if plasma.isDuration(value) {
+		return value.getDuration(), nil
+	}
+	var nanoseconds float64
+	switch value.TypeId() {
+	case IntId:
+		if !value.isBigInt() && value.GetInt64() > math.MinInt64/int64(time.Second) &&
+			value.GetInt64() < math.MaxInt64/int64(time.Second) {
+			return time.Duration(value.GetInt64()) * time.Second, nil
+		}
+		return 0, DurationOutOfRange
+	case FloatId:
+		nanoseconds = math.Round(value.GetFloat64() * float64(time.Second))
+	default:
+		return 0, NotOperable
+	}
+	if math.IsNaN(nanoseconds) || nanoseconds <= math.MinInt64 || nanoseconds >= math.MaxInt64 {
+		return 0, DurationOutOfRange
+	}
+	return time.Duration(nanoseconds), nil
+}
+
+func addDurations(a, b time.Duration) (time.Duration, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, DurationOutOfRange
+	}
+	return sum, nil
+}
+
+func (plasma *Plasma) timeZone(value *Value) (*time.Location, error) {
+	if value.TypeId() != StringId {
+		return nil, UnknownTimeZone
+	}
+	return loadZone(value.String())
+}
+
+// compareMethods sets the ordering methods of objects whose values are compared by cmp
+func (plasma *Plasma) compareMethods(result *Value, same func(*Value) bool, cmp func(other *Value) int) {
+	for name, accepts := range map[string]func(int) bool{
+		magic_functions.GreaterThan:        func(c int) bool { return c > 0 },
+		magic_functions.GreaterOrEqualThan: func(c int) bool { return c >= 0 },
+		magic_functions.LessThan:           func(c int) bool { return c < 0 },
+		magic_functions.LessOrEqualThan:    func(c int) bool { return c <= 0 },
+	} {
+		accepts := accepts
+		plasma.define(result.vtable, name, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			if !same(argument[0]) {
+				return nil, NotComparable
+			}
+			return plasma.NewBool(accepts(cmp(argument[0]))), nil
+		})
+	}
+	plasma.define(result.vtable, magic_functions.Equal, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(same(argument[0]) && cmp(argument[0]) == 0), nil
+	})
+	plasma.define(result.vtable, magic_functions.NotEqual, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewBool(!same(argument[0]) || cmp(argument[0]) != 0), nil
+	})
+}
+
+func (plasma *Plasma) durationClass() *Value {
+	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
+	class.SetAny(constructor(magic_functions.Duration, []Parameter{param("seconds")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		d, durationError := plasma.toDuration(argument[0])
+		if durationError != nil {
+			return nil, durationError
+		}
+		return plasma.NewDuration(d), nil
+	}))
+	return class
+}
+
+/*
+NewDuration magic function:
+Positive:           __positive__
+Negative:           __negative__
+Equal:              __equal__
+NotEqual:           __not_equal__
+GreaterThan:        __greater_than__
+GreaterOrEqualThan: __greater_or_equal_than__
+LessThan:           __less_than__
+LessOrEqualThan:    __less_or_equal_than__
+Add:                __add__
+Sub:                __sub__
+Mul:                __mul__
+Div:                __div__
+Hash:               __hash__
+String:             __string__
+Seconds				seconds
+Millis				milliseconds
+*/
+func (plasma *Plasma) NewDuration(d time.Duration) *Value {
+	result := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.duration)
+	result.SetAny(d)
+	plasma.compareMethods(result, plasma.isDuration, func(other *Value) int {
+		switch otherDuration := other.getDuration(); {
+		case d < otherDuration:
+			return -1
+		case d > otherDuration:
+			return 1
+		}
+		return 0
+	})
+	plasma.define(result.vtable, magic_functions.Positive, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return result, nil
+	})
+	plasma.define(result.vtable, magic_functions.Negative, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if d == math.MinInt64 {
+			return nil, DurationOutOfRange
+		}
+		return plasma.NewDuration(-d), nil
+	})
+	plasma.define(result.vtable, magic_functions.Add, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if plasma.isDateTime(argument[0]) {
+			return plasma.NewDateTime(argument[0].getTime().Add(d)), nil
+		}
+		if !plasma.isDuration(argument[0]) {
+			return nil, NotOperable
+		}
+		sum, sumError := addDurations(d, argument[0].getDuration())
+		if sumError != nil {
+			return nil, sumError
+		}
+		return plasma.NewDuration(sum), nil
+	})
+	plasma.define(result.vtable, magic_functions.Sub, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if !plasma.isDuration(argument[0]) || argument[0].getDuration() == math.MinInt64 {
+			return nil, NotOperable
+		}
+		difference, differenceError := addDurations(d, -argument[0].getDuration())
+		if differenceError != nil {
+			return nil, differenceError
+		}
+		return plasma.NewDuration(difference), nil
+	})
+	plasma.define(result.vtable, magic_functions.Mul, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			product := math.Round(float64(d) * argument[0].Float())
+			if math.IsNaN(product) || product <= math.MinInt64 || product >= math.MaxInt64 {
+				return nil, DurationOutOfRange
+			}
+			return plasma.NewDuration(time.Duration(product)), nil
+		}
+		return nil, NotOperable
+	})
+	// Dividing by a number scales the duration, dividing by another duration returns their ratio
+	plasma.define(result.vtable, magic_functions.Div, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if plasma.isDuration(argument[0]) {
+			return plasma.NewFloat(float64(d) / float64(argument[0].getDuration())), nil
+		}
+		switch argument[0].TypeId() {
+		case IntId, FloatId:
+			quotient := math.Round(float64(d) / argument[0].Float())
+			if math.IsNaN(quotient) || quotient <= math.MinInt64 || quotient >= math.MaxInt64 {
+				return nil, DurationOutOfRange
+			}
+			return plasma.NewDuration(time.Duration(quotient)), nil
+		}
+		return nil, NotOperable
+	})
+	plasma.define(result.vtable, magic_functions.Hash, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(int64(d)), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(d.String())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Seconds, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(d.Seconds()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Millis, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(d.Milliseconds()), nil
+	})
+	return result
+}
+
+var dateTimeParameters = []Parameter{
+	param("year", IntId), param("month", IntId), param("day", IntId),
+	optional("hour", IntId), optional("minute", IntId), optional("second", IntId), optional("nanosecond", IntId),
+	optional("zone", StringId),
+}
+
+func (plasma *Plasma) dateTimeClass() *Value {
+	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
+	class.SetAny(constructor(magic_functions.DateTime, dateTimeParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		fields := []int{0, 0, 0, 0, 0, 0, 0}
+		location := time.UTC
+		for index, field := range argument {
+			if index == len(fields) {
+				var zoneError error
+				location, zoneError = plasma.timeZone(field)
+				if zoneError != nil {
+					return nil, zoneError
+				}
+				break
+			}
+			fields[index] = int(field.Int())
+		}
+		t, dateError := makeDate(fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], location)
+		if dateError != nil {
+			return nil, dateError
+		}
+		return plasma.NewDateTime(t), nil
+	}))
+	plasma.define(class.vtable, magic_functions.FromUnix, []Parameter{param("seconds")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		d, durationError := plasma.toDuration(argument[0])
+		if durationError != nil {
+			return nil, durationError
+		}
+		return plasma.NewDateTime(time.Unix(0, 0).UTC().Add(d)), nil
+	})
+	return class
+}
+
+/*
+NewDateTime magic function:
+Equal:              __equal__
+NotEqual:           __not_equal__
+GreaterThan:        __greater_than__
+GreaterOrEqualThan: __greater_or_equal_than__
+LessThan:           __less_than__
+LessOrEqualThan:    __less_or_equal_than__
+Add:                __add__
+Sub:                __sub__
+Hash:               __hash__
+String:             __string__
+Year				year
+Month				month
+Day					day
+Hour				hour
+Minute				minute
+Second				second
+Nanosecond			nanosecond
+Weekday				weekday
+YearDay				year_day
+Unix				unix
+Zone				zone
+InZone				in_zone
+UTC					utc
+Format				format
+*/
+func (plasma *Plasma) NewDateTime(t time.Time) *Value {
+	result := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.dateTime)
+	result.SetAny(t)
+	// Date times are equal when they represent the same instant, no matter their zones
+	plasma.compareMethods(result, plasma.isDateTime, func(other *Value) int {
+		switch otherTime := other.getTime(); {
+		case t.Before(otherTime):
+			return -1
+		case t.After(otherTime):
+			return 1
+		}
+		return 0
+	})
+	for name, field := range map[string]func() int{
+		magic_functions.Year:       t.Year,
+		magic_functions.Month:      func() int { return int(t.Month()) },
+		magic_functions.Day:        t.Day,
+		magic_functions.Hour:       t.Hour,
+		magic_functions.Minute:     t.Minute,
+		magic_functions.Second:     t.Second,
+		magic_functions.Nanosecond: t.Nanosecond,
+		magic_functions.Weekday:    func() int { return int(t.Weekday()) },
+		magic_functions.YearDay:    t.YearDay,
+	} {
+		field := field
+		plasma.define(result.vtable, name, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+			return plasma.NewInt(int64(field())), nil
+		})
+	}
+	plasma.define(result.vtable, magic_functions.Add, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if !plasma.isDuration(argument[0]) {
+			return nil, NotOperable
+		}
+		return plasma.NewDateTime(t.Add(argument[0].getDuration())), nil
+	})
+	// Subtracting a date time returns the duration between both, subtracting a duration moves the date time
+	plasma.define(result.vtable, magic_functions.Sub, otherParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if plasma.isDateTime(argument[0]) {
+			return plasma.NewDuration(t.Sub(argument[0].getTime())), nil
+		}
+		if !plasma.isDuration(argument[0]) {
+			return nil, NotOperable
+		}
+		return plasma.NewDateTime(t.Add(-argument[0].getDuration())), nil
+	})
+	plasma.define(result.vtable, magic_functions.Hash, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(t.UnixNano()), nil
+	})
+	plasma.define(result.vtable, magic_functions.String, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewString([]byte(t.Format(time.RFC3339Nano))), nil
+	})
+	plasma.define(result.vtable, magic_functions.Unix, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewInt(t.Unix()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Zone, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		if name := t.Location().String(); name != "" {
+			return plasma.NewString([]byte(name)), nil
+		}
+		return plasma.NewString([]byte(t.Format("-07:00"))), nil
+	})
+	plasma.define(result.vtable, magic_functions.InZone, []Parameter{param("zone")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		location, zoneError := plasma.timeZone(argument[0])
+		if zoneError != nil {
+			return nil, zoneError
+		}
+		return plasma.NewDateTime(t.In(location)), nil
+	})
+	plasma.define(result.vtable, magic_functions.UTC, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewDateTime(t.UTC()), nil
+	})
+	plasma.define(result.vtable, magic_functions.Format, []Parameter{param("format", StringId)}, func(ctx *context, argument ...*Value) (*Value, error) {
+		s, formatError := strftime(t, argument[0].String())
+		if formatError != nil {
+			return nil, formatError
+		}
+		return plasma.NewString([]byte(s)), nil
+	})
+	return result
+}
+
+/*
+timeModule functions:
+Now			now
+Monotonic	monotonic
+Sleep		sleep
+Parse		parse
+Classes: DateTime, Duration
+*/
+func (plasma *Plasma) timeModule() *Value {
+	plasma.dateTime = plasma.dateTimeClass()
+	plasma.duration = plasma.durationClass()
+	module := plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value)
+	module.Set(magic_functions.DateTime, plasma.dateTime)
+	module.Set(magic_functions.Duration, plasma.duration)
+	plasma.define(module.vtable, magic_functions.Now, []Parameter{optional("zone")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		now := plasma.Clock().Now()
+		if len(argument) == 0 {
+			return plasma.NewDateTime(now), nil
+		}
+		location, zoneError := plasma.timeZone(argument[0])
+		if zoneError != nil {
+			return nil, zoneError
+		}
+		return plasma.NewDateTime(now.In(location)), nil
+	})
+	plasma.define(module.vtable, magic_functions.Monotonic, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
+		return plasma.NewFloat(plasma.Clock().Monotonic().Seconds()), nil
+	})
+	// The interpreter performs the wait, so stopping the execution also interrupts the sleep
+	plasma.define(module.vtable, magic_functions.Sleep, []Parameter{param("seconds")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		d, durationError := plasma.toDuration(argument[0])
+		if durationError != nil {
+			return nil, durationError
+		}
+		if d <= 0 {
+			return plasma.none, nil
+		}
+		return nil, &waitRequest{duration: d, result: plasma.none}
+	})
+	plasma.define(module.vtable, magic_functions.Parse, []Parameter{param("value", StringId), param("format", StringId), optional("zone")}, func(ctx *context, argument ...*Value) (*Value, error) {
+		location := time.UTC
+		if len(argument) > 2 {
+			var zoneError error
+			location, zoneError = plasma.timeZone(argument[2])
+			if zoneError != nil {
+				return nil, zoneError
+			}
+		}
+		t, parseError := strptime(argument[0].String(), argument[1].String(), location)
+		if parseError != nil {
+			return nil, parseError
+		}
+		return plasma.NewDateTime(t), nil
+	})
+	return module
+}
@@ -0,0 +1,135 @@
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/shoriwe/gplasma/pkg/bytecode/opcodes"
+)
+
+/*
+Tracer receives the events of the executions of a VM. The callbacks run synchronously in the
+goroutine of the execution, so they delay it and must not call back into the VM.
+Executions keep the tracer set when they started, without one the VM only checks for nil.
+*/
+type Tracer interface {
+	// Step is called before running the instruction at the offset of the current code
+	Step(opcode byte, offset int64)
+	// Call is called before calling a value, Return when the call ends with its result
+	Call(function *Value, argument []*Value)
+	Return(function, result *Value)
+	// GetSymbol and SetSymbol follow the identifiers read and assigned by the scripts
+	GetSymbol(symbol string, value *Value)
+	SetSymbol(symbol string, value *Value)
+	// Error receives the error ending the execution
+	Error(err error)
+}
+
+func (plasma *Plasma) SetTracer(tracer Tracer) {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	plasma.tracer = tracer
+}
+
+func (plasma *Plasma) Tracer() Tracer {
+	plasma.hostMutex.Lock()
+	defer plasma.hostMutex.Unlock()
+	return plasma.tracer
+}
+
+/*
+JSONTracer writes every event as a JSON object in its own line, like
+{"arguments":["hello"],"event":"call","function":"println"}.
+Scalars are written as JSON values and other values by their type, so script code
+like __repr__ never runs while tracing. The first write error stops the tracing.
+*/
+type JSONTracer struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+func NewJSONTracer(writer io.Writer) *JSONTracer {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return &JSONTracer{encoder: encoder}
+}
+
+// Err returns the error that stopped the tracing
+func (tracer *JSONTracer) Err() error {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	return tracer.err
+}
+
+func (tracer *JSONTracer) write(event map[string]any) {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	if tracer.err != nil {
+		return
+	}
+	tracer.err = tracer.encoder.Encode(event)
+}
+
+func (tracer *JSONTracer) Step(opcode byte, offset int64) {
+	name, found := opcodes.OpCodes[opcode]
+	if !found {
+		name = fmt.Sprint(opcode)
+	}
+	tracer.write(map[string]any{"event": "step", "opcode": name, "offset": offset})
+}
+
+func (tracer *JSONTracer) Call(function *Value, argument []*Value) {
+	arguments := make([]any, 0, len(argument))
+	for _, value := range argument {
+		arguments = append(arguments, traceValue(value))
+	}
+	tracer.write(map[string]any{"event": "call", "function": traceValue(function), "arguments": arguments})
+}
+
+func (tracer *JSONTracer) Return(function, result *Value) {
+	tracer.write(map[string]any{"event": "return", "function": traceValue(function), "result": traceValue(result)})
+}
+
+func (tracer *JSONTracer) GetSymbol(symbol string, value *Value) {
+	tracer.write(map[string]any{"event": "get", "symbol": symbol, "value": traceValue(value)})
+}
+
+func (tracer *JSONTracer) SetSymbol(symbol string, value *Value) {
+	tracer.write(map[string]any{"event": "set", "symbol": symbol, "value": traceValue(value)})
+}
+
+func (tracer *JSONTracer) Error(err error) {
+	tracer.write(map[string]any{"event": "error", "error": err.Error()})
+}
+
+// traceValue describes the value without calling any of its methods
+func traceValue(value *Value) any {
+	if value == nil {
+		return nil
+	}
+	switch value.TypeId() {
+	case NoneId:
+		return nil
+	case BoolId:
+		return value.GetBool()
+	case IntId:
+		return json.Number(value.String())
+	case FloatId:
+		f := value.GetFloat64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprint(f)
+		}
+		return f
+	case StringId, BytesId:
+		return string(value.GetBytes())
+	case BuiltInFunctionId, BuiltInClassId:
+		if signature, ok := value.Signature(); ok {
+			return signature.Name
+		}
+	}
+	return "<" + value.TypeId().String() + ">"
+}
@@ -3,8 +3,11 @@ package vm
 import (
 	"bytes"
 	"fmt"
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	special_symbols "github.com/shoriwe/gplasma/pkg/common/special-symbols"
 	"github.com/shoriwe/gplasma/pkg/lexer"
 	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -22,6 +25,7 @@ const (
 	FunctionId
 	BuiltInClassId
 	ClassId
+	SetId
 )
 
 type (
@@ -30,25 +34,36 @@ type (
 	FuncInfo struct {
 		Arguments []string
 		Bytecode  []byte
+		cache     *inlineCache
 	}
 	ClassInfo struct {
 		prepared bool
 		Bases    []*Value
 		Bytecode []byte
+		cache    *inlineCache
 	}
 	Value struct {
+		plasma   *Plasma
 		onDemand map[string]func(self *Value) *Value
-		class    *Value
-		typeId   TypeId
-		mutex    *sync.Mutex
-		v        any
-		vtable   *Symbols
+		// properties of objects created by a ClassBuilder, they take precedence over the virtual table.
+		// hasProperties is set once they are, so the other objects look up attributes without locking
+		properties    map[string]*property
+		hasProperties uint32
+		class         *Value
+		typeId        TypeId
+		mutex         *sync.Mutex
+		v             any
+		vtable        *Symbols
+		// frozen values are shared by every scope, scripts can not assign or delete their attributes
+		frozen bool
+		// fromOnDemand values were created by the on demand functions of the value holding them
+		fromOnDemand bool
 	}
 )
 
 func (plasma *Plasma) valueClass() *Value {
 	class := plasma.NewValue(plasma.rootSymbols, BuiltInClassId, plasma.class)
-	class.SetAny(Callback(func(argument ...*Value) (*Value, error) {
+	class.SetAny(constructor(special_symbols.Value, noParameters, func(ctx *context, argument ...*Value) (*Value, error) {
 		return plasma.NewValue(plasma.rootSymbols, ValueId, plasma.value), nil
 	}))
 	return class
@@ -87,6 +102,11 @@ func (value *Value) GetHash() *Hash {
 func (value *Value) GetCallback() Callback {
 	value.mutex.Lock()
 	defer value.mutex.Unlock()
+	if b, ok := value.v.(*builtIn); ok {
+		return func(argument ...*Value) (*Value, error) {
+			return b.call(nil, argument)
+		}
+	}
 	return value.v.(Callback)
 }
 
@@ -143,6 +163,9 @@ func (value *Value) Set(symbol string, v *Value) {
 }
 
 func (value *Value) Get(symbol string) (*Value, error) {
+	if p, found := value.getProperty(symbol); found {
+		return p.get(value)
+	}
 	result, getError := value.vtable.Get(symbol)
 	if getError == nil {
 		return result, nil
@@ -154,14 +177,68 @@ func (value *Value) Get(symbol string) (*Value, error) {
 		return nil, SymbolNotFoundError
 	}
 	result = onDemand(value)
+	result.mutex.Lock()
+	result.fromOnDemand = true
+	result.mutex.Unlock()
+	if value.frozen {
+		result.freeze()
+	}
 	value.vtable.Set(symbol, result)
 	return result, nil
 }
 
+func (value *Value) getProperty(symbol string) (*property, bool) {
+	if atomic.LoadUint32(&value.hasProperties) == 0 {
+		return nil, false
+	}
+	value.mutex.Lock()
+	defer value.mutex.Unlock()
+	p, found := value.properties[symbol]
+	return p, found
+}
+
+// Assign sets the attribute the way scripts do, going through the property setter when there is one
+func (value *Value) Assign(symbol string, v *Value) error {
+	if value.isFrozen() {
+		return FrozenValue
+	}
+	if p, found := value.getProperty(symbol); found {
+		return p.set(value, v)
+	}
+	value.Set(symbol, v)
+	return nil
+}
+
 func (value *Value) Del(symbol string) error {
 	return value.vtable.Del(symbol)
 }
 
+func (value *Value) isOnDemand() bool {
+	value.mutex.Lock()
+	defer value.mutex.Unlock()
+	return value.fromOnDemand
+}
+
+func (value *Value) isFrozen() bool {
+	value.mutex.Lock()
+	defer value.mutex.Unlock()
+	return value.frozen
+}
+
+// freeze also freezes the attributes of the value, the ones created on demand are frozen by Get
+func (value *Value) freeze() {
+	value.mutex.Lock()
+	if value.frozen {
+		value.mutex.Unlock()
+		return
+	}
+	value.frozen = true
+	value.mutex.Unlock()
+	for _, attribute := range value.vtable.locals() {
+		attribute.freeze()
+	}
+}
+
 func (value *Value) Bool() bool {
 	switch value.TypeId() {
 	case ValueId:
@@ -173,12 +250,15 @@ func (value *Value) Bool() bool {
 	case NoneId:
 		return false
 	case IntId:
+		if value.isBigInt() {
+			return true
+		}
 		return value.GetInt64() != 0
 	case FloatId:
 		return value.GetFloat64() != 0
 	case ArrayId, TupleId:
 		return len(value.GetValues()) > 0
-	case HashId:
+	case HashId, SetId:
 		return value.GetHash().Size() > 0
 	case BuiltInFunctionId:
 		return true
@@ -206,15 +286,16 @@ func (value *Value) String() string {
 	case NoneId:
 		return lexer.NoneString
 	case IntId:
+		if value.isBigInt() {
+			return value.GetBigInt().String()
+		}
 		return fmt.Sprintf("%d", value.GetInt64())
 	case FloatId:
 		return fmt.Sprintf("%f", value.GetFloat64())
-	case ArrayId:
-		return "[...]"
-	case TupleId:
-		return "(...)"
-	case HashId:
-		return "{...}"
+	case ArrayId, TupleId, HashId, SetId:
+		// Without the VM objects inside the container can not be rendered through their methods
+		s, _ := (&renderer{}).render(value, false)
+		return s
 	case BuiltInFunctionId:
 		return "?BuiltInFunction"
 	case FunctionId:
@@ -245,7 +326,7 @@ func (value *Value) Contents() []byte {
 		return nil
 	case TupleId:
 		return nil
-	case HashId:
+	case HashId, SetId:
 		return nil
 	case BuiltInFunctionId:
 		return nil
@@ -275,6 +356,9 @@ func (value *Value) Int() int64 {
 	case NoneId:
 		return 0
 	case IntId:
+		if value.isBigInt() {
+			return value.GetBigInt().Int64()
+		}
 		return value.GetInt64()
 	case FloatId:
 		return int64(value.GetFloat64())
@@ -282,7 +366,7 @@ func (value *Value) Int() int64 {
 		return 0
 	case TupleId:
 		return 0
-	case HashId:
+	case HashId, SetId:
 		return 0
 	case BuiltInFunctionId:
 		return 0
@@ -312,6 +396,9 @@ func (value *Value) Float() float64 {
 	case NoneId:
 		return 0
 	case IntId:
+		if value.isBigInt() {
+			return bigIntToFloat(value.GetBigInt())
+		}
 		return float64(value.GetInt64())
 	case FloatId:
 		return value.GetFloat64()
@@ -319,7 +406,7 @@ func (value *Value) Float() float64 {
 		return 0
 	case TupleId:
 		return 0
-	case HashId:
+	case HashId, SetId:
 		return 0
 	case BuiltInFunctionId:
 		return 0
@@ -351,7 +438,7 @@ func (value *Value) Values() []*Value {
 		return nil
 	case ArrayId, TupleId:
 		return value.GetValues()
-	case HashId:
+	case HashId, SetId:
 		return nil
 	case BuiltInFunctionId:
 		return nil
@@ -366,6 +453,14 @@ func (value *Value) Values() []*Value {
 }
 
 func (value *Value) Call(argument ...*Value) (*Value, error) {
+	return value.call(nil, argument...)
+}
+
+// call passes the context of the calling execution to the built-ins receiving it
+func (value *Value) call(ctx *context, argument ...*Value) (*Value, error) {
+	if b, ok := value.GetAny().(*builtIn); ok {
+		return b.call(ctx, argument)
+	}
 	return value.GetCallback()(argument...)
 }
 
@@ -381,54 +476,156 @@ func (value *Value) Implements(class *Value) bool {
 	return false
 }
 
+// Equal compares the values structurally, errors raised by user defined __equal__ are treated as not equal
 func (value *Value) Equal(other *Value) bool {
+	equal, _ := value.equals(nil, other, nil)
+	return equal
+}
+
+// Equals is Equal reporting the errors raised by user defined __equal__
+func (value *Value) Equals(other *Value) (bool, error) {
+	return value.equals(nil, other, nil)
+}
+
+type comparedPair struct {
+	a, b *Value
+}
+
+/*
+equals dispatches the comparison by type, seen holds the container pairs already under comparison,
+when a pair is found again it is assumed equal so cyclic structures terminate
+*/
+func (value *Value) equals(ctx *context, other *Value, seen map[comparedPair]struct{}) (bool, error) {
 	switch value.TypeId() {
 	case ValueId:
-		return value.ValueEqual(other)
+		return value.valueEqual(ctx, other)
 	case StringId:
-		return value.StringEqual(other)
+		return value.StringEqual(other), nil
 	case BytesId:
-		return value.BytesEqual(other)
+		return value.BytesEqual(other), nil
 	case BoolId:
-		return value.BoolEqual(other)
+		return value.BoolEqual(other), nil
 	case NoneId:
-		return value.NoneEqual(other)
+		return value.NoneEqual(other), nil
 	case IntId:
-		return value.IntEqual(other)
+		return value.IntEqual(other), nil
 	case FloatId:
-		return value.FloatEqual(other)
-	case ArrayId:
-		return value.ArrayEqual(other)
-	case TupleId:
-		return value.TupleEqual(other)
-	case HashId:
-		return value.HashEqual(other)
+		return value.FloatEqual(other), nil
+	case ArrayId, TupleId:
+		if other.TypeId() != value.TypeId() {
+			return false, nil
+		}
+		return value.sequenceEqual(ctx, other, seen)
+	case HashId, SetId:
+		if other.TypeId() != value.TypeId() {
+			return false, nil
+		}
+		return value.hashEqual(ctx, other, seen)
 	case BuiltInFunctionId:
-		return value.BuiltInFunctionEqual(other)
+		return value.BuiltInFunctionEqual(other), nil
 	case FunctionId:
-		return value.FunctionEqual(other)
+		return value.FunctionEqual(other), nil
 	case BuiltInClassId:
-		return value.BuiltInClassEqual(other)
+		return value.BuiltInClassEqual(other), nil
 	case ClassId:
-		return value.ClassEqual(other)
+		return value.ClassEqual(other), nil
 	}
-	return false
+	return false, nil
+}
+
+func (value *Value) enterComparison(other *Value, seen map[comparedPair]struct{}) (map[comparedPair]struct{}, bool) {
+	if seen == nil {
+		seen = map[comparedPair]struct{}{}
+	}
+	pair := comparedPair{a: value, b: other}
+	if _, found := seen[pair]; found {
+		return seen, false
+	}
+	seen[pair] = struct{}{}
+	return seen, true
+}
+
+func (value *Value) sequenceEqual(ctx *context, other *Value, seen map[comparedPair]struct{}) (bool, error) {
+	if value == other {
+		return true, nil
+	}
+	values, otherValues := value.GetValues(), other.GetValues()
+	if len(values) != len(otherValues) {
+		return false, nil
+	}
+	seen, first := value.enterComparison(other, seen)
+	if !first {
+		return true, nil
+	}
+	for index, internalValue := range values {
+		equal, equalError := internalValue.equals(ctx, otherValues[index], seen)
+		if equalError != nil || !equal {
+			return false, equalError
+		}
+	}
+	return true, nil
+}
+
+func (value *Value) hashEqual(ctx *context, other *Value, seen map[comparedPair]struct{}) (bool, error) {
+	if value == other {
+		return true, nil
+	}
+	hash, otherHash := value.GetHash(), other.GetHash()
+	if hash.Size() != otherHash.Size() {
+		return false, nil
+	}
+	seen, first := value.enterComparison(other, seen)
+	if !first {
+		return true, nil
+	}
+	for _, item := range hash.Items() {
+		found, inError := otherHash.contains(ctx, item.Key)
+		if inError != nil || !found {
+			return false, inError
+		}
+		otherValue, getError := otherHash.get(ctx, item.Key)
+		if getError != nil {
+			return false, getError
+		}
+		equal, equalError := item.Value.equals(ctx, otherValue, seen)
+		if equalError != nil || !equal {
+			return false, equalError
+		}
+	}
+	return true, nil
+}
+
+// valueEqual follows the __equal__ defined by the object class, objects without it are compared by identity
+func (value *Value) valueEqual(ctx *context, other *Value) (bool, error) {
+	if value == other {
+		return true, nil
+	}
+	equalFunc, getError := value.vtable.Get(magic_functions.Equal)
+	if getError != nil || value.plasma == nil {
+		return false, nil
+	}
+	result, callError := value.plasma.call(ctx, equalFunc, other)
+	if callError != nil {
+		return false, callError
+	}
+	return result.Bool(), nil
 }
 
 func (value *Value) ValueEqual(other *Value) bool {
-	return value == other
+	equal, _ := value.valueEqual(nil, other)
+	return equal
 }
 
 func (value *Value) StringEqual(other *Value) bool {
-	return value.String() == other.String()
+	return other.TypeId() == StringId && bytes.Equal(value.GetBytes(), other.GetBytes())
 }
 
 func (value *Value) BytesEqual(other *Value) bool {
-	return bytes.Equal(value.GetBytes(), other.GetBytes())
+	return other.TypeId() == BytesId && bytes.Equal(value.GetBytes(), other.GetBytes())
 }
 
 func (value *Value) BoolEqual(other *Value) bool {
-	return value.Bool() == other.Bool()
+	return other.TypeId() == BoolId && value.GetBool() == other.GetBool()
 }
 
 func (value *Value) NoneEqual(other *Value) bool {
@@ -437,63 +634,30 @@ func (value *Value) NoneEqual(other *Value) bool {
 
 func (value *Value) IntEqual(other *Value) bool {
 	switch other.TypeId() {
-	case IntId:
-		return value.Int() == other.Int()
-	case FloatId:
-		return value.Float() == other.Float()
+	case IntId, FloatId:
+		return numericEqual(value, other)
 	}
 	return false
 }
 
 func (value *Value) FloatEqual(other *Value) bool {
 	switch other.TypeId() {
-	case IntId:
-		return value.Int() == other.Int()
-	case FloatId:
-		return value.Float() == other.Float()
+	case IntId, FloatId:
+		return numericEqual(value, other)
 	}
 	return false
 }
 
 func (value *Value) ArrayEqual(other *Value) bool {
-	switch other.TypeId() {
-	case ArrayId:
-		values := value.GetValues()
-		otherValues := other.GetValues()
-		if len(values) != len(otherValues) {
-			return false
-		}
-		for index, internalValue := range values {
-			if !internalValue.Equal(otherValues[index]) {
-				return false
-			}
-		}
-		return true
-	}
-	return false
+	return other.TypeId() == ArrayId && value.Equal(other)
 }
 
 func (value *Value) TupleEqual(other *Value) bool {
-	switch other.TypeId() {
-	case TupleId:
-		values := value.GetValues()
-		otherValues := other.GetValues()
-		if len(values) != len(otherValues) {
-			return false
-		}
-		for index, internalValue := range values {
-			if !internalValue.Equal(otherValues[index]) {
-				return false
-			}
-		}
-		return true
-	}
-	return false
+	return other.TypeId() == TupleId && value.Equal(other)
 }
 
 func (value *Value) HashEqual(other *Value) bool {
-	// TODO: implement me!
-	return value == other
+	return other.TypeId() == HashId && value.Equal(other)
 }
 
 func (value *Value) BuiltInFunctionEqual(other *Value) bool {
@@ -524,6 +688,7 @@ Class              __class__
 */
 func (plasma *Plasma) NewValue(parent *Symbols, typeId TypeId, class *Value) *Value {
 	return &Value{
+		plasma:   plasma,
 		onDemand: plasma.onDemand,
 		class:    class,
 		typeId:   typeId,
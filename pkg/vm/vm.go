@@ -1,16 +1,32 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
+	"github.com/shoriwe/gplasma/pkg/bytecode/opcodes"
+	"github.com/shoriwe/gplasma/pkg/common"
 	"github.com/shoriwe/gplasma/pkg/compiler"
 	"io"
+	"sync"
+	"time"
 )
 
+var ExecutionStopped = fmt.Errorf("execution stopped")
+
 type (
 	Loader func(plasma *Plasma) *Value
 	Plasma struct {
 		Stdin             io.Reader
 		Stdout, Stderr    io.Writer
+		cacheHits         uint64
+		cacheMisses       uint64
+		streams           streams
+		hostMutex         sync.Mutex
+		clock             Clock
+		fileSystem        FileSystem
+		os                OS
+		tracer            Tracer
+		random            *randomSource
 		rootSymbols       *Symbols
 		onDemand          map[string]func(self *Value) *Value
 		true, false, none *Value
@@ -24,6 +40,9 @@ type (
 		array             *Value
 		tuple             *Value
 		hash              *Value
+		set               *Value
+		dateTime          *Value
+		duration          *Value
 		function          *Value
 		class             *Value
 	}
@@ -85,6 +104,10 @@ func (plasma *Plasma) Hash() *Value {
 	return plasma.hash
 }
 
+func (plasma *Plasma) Set() *Value {
+	return plasma.set
+}
+
 func (plasma *Plasma) Function() *Value {
 	return plasma.function
 }
@@ -97,7 +120,12 @@ func (plasma *Plasma) executeCtx(ctx *context) {
 	defer func() {
 		err := recover()
 		if err != nil {
+			if ctx.tracer != nil {
+				ctx.traceError(err)
+			}
 			ctx.err <- fmt.Errorf("execution error: %v", err)
+		} else if ctx.exit != nil {
+			ctx.err <- ctx.exit
 		} else {
 			ctx.err <- nil
 		}
@@ -114,19 +142,79 @@ func (plasma *Plasma) executeCtx(ctx *context) {
 	}
 }
 
+/*
+CallFunction synchronously calls any callable value (built-in functions, functions, classes
+and objects implementing __call__). It let Go code invoke callbacks defined by scripts.
+*/
+func (plasma *Plasma) CallFunction(function *Value, argument ...*Value) (*Value, error) {
+	return plasma.call(nil, function, argument...)
+}
+
+/*
+call is CallFunction made by the built-ins of the caller execution, the script code honours
+its stop channel and ExecutionStopped is returned once it is signaled.
+*/
+func (plasma *Plasma) call(caller *context, function *Value, argument ...*Value) (result *Value, err error) {
+	switch function.TypeId() {
+	case BuiltInFunctionId, BuiltInClassId:
+		result, err = function.call(caller, argument...)
+		var request *waitRequest
+		if errors.As(err, &request) {
+			if value, done := plasma.wait(caller, request); done {
+				return value, nil
+			}
+			return nil, ExecutionStopped
+		}
+		return result, err
+	}
+	callCode := make([]byte, 0, 9)
+	callCode = append(callCode, opcodes.Call)
+	callCode = append(callCode, common.IntToBytes(len(argument))...)
+	ctx := plasma.newContext(callCode)
+	if caller != nil {
+		ctx.stop, ctx.renderer = caller.stop, caller.renderer
+	}
+	for _, arg := range argument {
+		ctx.stack.Push(arg)
+	}
+	ctx.stack.Push(function)
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		if recoveredError, ok := recovered.(error); ok {
+			err = recoveredError
+		} else {
+			err = fmt.Errorf("execution error: %v", recovered)
+		}
+	}()
+	for ctx.hasNext() {
+		select {
+		case signal := <-ctx.stop:
+			// Put the signal back so every caller up to the execution loop stops too
+			signalStop(ctx.stop, signal)
+			return nil, ExecutionStopped
+		default:
+			plasma.do(ctx)
+		}
+	}
+	if ctx.exit != nil {
+		return nil, ctx.exit
+	}
+	if ctx.register == nil {
+		return plasma.none, nil
+	}
+	return ctx.register, nil
+}
+
 func (plasma *Plasma) Load(symbol string, loader Loader) {
 	plasma.rootSymbols.Set(symbol, loader(plasma))
 }
 
+// Execute runs the bytecode in the root symbols, its globals are visible by the next executions, see NewScope
 func (plasma *Plasma) Execute(bytecode []byte) (result chan *Value, err chan error, stop chan struct{}) {
-	// Create new context
-	ctx := plasma.newContext(bytecode)
-	ctx.result = make(chan *Value, 1)
-	ctx.err = make(chan error, 1)
-	ctx.stop = make(chan struct{}, 1)
-	// Execute bytecode with context
-	go plasma.executeCtx(ctx)
-	return ctx.result, ctx.err, ctx.stop
+	return plasma.ExecuteIn(plasma.rootSymbols, bytecode)
 }
 
 func (plasma *Plasma) ExecuteString(scriptCode string) (result chan *Value, err chan error, stop chan struct{}) {
@@ -152,6 +240,8 @@ func NewVM(stdin io.Reader, stdout, stderr io.Writer) *Plasma {
 		Stdout:      stdout,
 		Stderr:      stderr,
 		rootSymbols: NewSymbols(nil),
+		clock:       &realClock{start: time.Now()},
+		random:      newRandomSource(cryptoSeed()),
 	}
 	plasma.init()
 	return plasma
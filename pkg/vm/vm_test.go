@@ -2,9 +2,14 @@ package vm
 
 import (
 	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/shoriwe/gplasma/pkg/ast"
 	"github.com/shoriwe/gplasma/pkg/bytecode/assembler"
+	magic_functions "github.com/shoriwe/gplasma/pkg/common/magic-functions"
+	"github.com/shoriwe/gplasma/pkg/compiler"
 	"github.com/shoriwe/gplasma/pkg/lexer"
 	"github.com/shoriwe/gplasma/pkg/parser"
 	"github.com/shoriwe/gplasma/pkg/passes/checks"
@@ -13,7 +18,17 @@ import (
 	"github.com/shoriwe/gplasma/pkg/reader"
 	"github.com/shoriwe/gplasma/pkg/test-samples/fail"
 	"github.com/shoriwe/gplasma/pkg/test-samples/success"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func TestSuccessSampleScripts(t *testing.T) {
@@ -107,3 +122,1465 @@ func TestFailSampleScripts(t *testing.T) {
 		}
 	}
 }
+
+func TestInlineCacheStats(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	_, err, _ := v.ExecuteString(`
+a = [1, 2, 3]
+for i in range(0, 100)
+    a.append(i)
+end
+println(a.__len__())
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if out.String() != "103\n" {
+		t.Fatal("Invalid result")
+	}
+	stats := v.InlineCacheStats()
+	if stats.Hits < 100 {
+		t.Fatalf("expecting at least 100 hits, obtained %d", stats.Hits)
+	}
+	t.Logf("Hit ratio: %f", stats.HitRatio())
+	v.ResetInlineCacheStats()
+	if v.InlineCacheStats() != (InlineCacheStats{}) {
+		t.Fatal("stats were not reset")
+	}
+}
+
+var comparisonConformance = []struct {
+	expression string
+	expected   string
+}{
+	// numbers
+	{"1 == 1.0", "true"},
+	{"1.0 == 1", "true"},
+	{"2 != 2.5", "true"},
+	{"9223372036854775808 == 9223372036854775808.0", "true"},
+	{"1 == true", "false"},
+	{"1 == \"1\"", "false"},
+	{"\"a\" == b\"a\"", "false"},
+	// arrays and tuples
+	{"[1, 2, 3] == [1, 2, 3]", "true"},
+	{"[1, 2, 3] == [1.0, 2.0, 3.0]", "true"},
+	{"[1, 2] == [1, 2, 3]", "false"},
+	{"[1, 2, 3] == [1, 2]", "false"},
+	{"[1, 2] == (1, 2)", "false"},
+	{"[1, 2] == 1", "false"},
+	{"[1, 2] != [1, 3]", "true"},
+	{"[] == []", "true"},
+	{"(1, (2, [3])) == (1, (2, [3]))", "true"},
+	{"(1, (2, [3])) == (1, (2, [4]))", "false"},
+	// hashes
+	{"{1: 2, 3: 4} == {3: 4, 1: 2}", "true"},
+	{"{1: [1, 2]} == {1: [1, 2]}", "true"},
+	{"{1: 2} == {1: 3}", "false"},
+	{"{1: 2} == {2: 2}", "false"},
+	{"{1: 2} == {1: 2, 3: 4}", "false"},
+	{"{1: 2} != {1: 2}", "false"},
+	{"{1: 2} == [1]", "false"},
+	// ordering
+	{"[1, 2, 3] < [1, 2, 4]", "true"},
+	{"[1, 2, 3] < [1, 2]", "false"},
+	{"[1, 2] < [1, 2, 3]", "true"},
+	{"[1, 2] <= [1, 2]", "true"},
+	{"[1, 2] >= [1, 2]", "true"},
+	{"[1, 2] > [1, 2]", "false"},
+	{"(2,) > (1, 100)", "true"},
+	{"(1, 2.5) < (1, 3)", "true"},
+	{"((1, 2), 3) < ((1, 3), 0)", "true"},
+	{"[] < [0]", "true"},
+}
+
+func TestComparisonConformance(t *testing.T) {
+	for _, test := range comparisonConformance {
+		out := &bytes.Buffer{}
+		v := NewVM(nil, out, out)
+		_, err, _ := v.ExecuteString("println(" + test.expression + ")")
+		if e := <-err; e != nil {
+			t.Fatalf("%s: %v", test.expression, e)
+		}
+		if result := out.String(); result != test.expected+"\n" {
+			t.Fatalf("%s: expecting %s but obtained %s", test.expression, test.expected, result)
+		}
+	}
+}
+
+func TestComparisonConformanceErrors(t *testing.T) {
+	for _, expression := range []string{
+		"[1] < (1,)",
+		"[1, 2] < [1, \"a\"]",
+		"(1,) < 1",
+		"[none] < [1]",
+	} {
+		v := NewVM(nil, io.Discard, io.Discard)
+		_, err, _ := v.ExecuteString("println(" + expression + ")")
+		if e := <-err; e == nil {
+			t.Fatalf("%s: should fail", expression)
+		}
+	}
+}
+
+func TestIntegerSizeBound(t *testing.T) {
+	for _, expression := range []string{
+		"2 ** 10000000",
+		"3 ** (2 ** 70)",
+		"1 << 10000000",
+		"(2 ** 1000000) << 100000",
+	} {
+		v := NewVM(nil, io.Discard, io.Discard)
+		_, err, _ := v.ExecuteString("println(" + expression + ")")
+		if e := <-err; e == nil || !strings.Contains(e.Error(), IntegerTooLarge.Error()) {
+			t.Fatalf("%s: expecting integer too large, obtained %v", expression, e)
+		}
+	}
+	var output bytes.Buffer
+	v := NewVM(nil, &output, io.Discard)
+	_, err, _ := v.ExecuteString(`
+println(1 ** (2 ** 70), (-1) ** 10000001, 0 ** 10000000)
+println(0 << 10000000, (2 ** 100) >> 99)
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if output.String() != "1 -1 0\n0 2\n" {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+}
+
+func TestJustify(t *testing.T) {
+	for script, expect := range map[string]error{
+		`"a".center(5, "xy")`: InvalidFill,
+		`"a".ljust(5, "")`:    InvalidFill,
+		`b"a".rjust(5, "é")`:  InvalidFill,
+		`"a".rjust(2 ** 40)`:  TextTooLong,
+		`"a".center(2 ** 70)`: TextTooLong,
+	} {
+		v := NewVM(nil, io.Discard, io.Discard)
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), expect.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, expect, e)
+		}
+	}
+	var output bytes.Buffer
+	v := NewVM(nil, &output, io.Discard)
+	_, err, _ := v.ExecuteString(`println("a".center(5, "é"), b"a".ljust(3, b"-"), "a".rjust(-(2 ** 70)), "ab".center(5))`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if output.String() != "ééaéé a-- a  ab  \n" {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	for _, script := range []string{
+		`"ab".repeat(2 ** 30)`,
+		`b"a".repeat(2 ** 70)`,
+		`"ab" * 2 ** 64`,
+		`(2 ** 31) * b"ab"`,
+	} {
+		v := NewVM(nil, io.Discard, io.Discard)
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), TextTooLong.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, TextTooLong, e)
+		}
+	}
+	var output bytes.Buffer
+	v := NewVM(nil, &output, io.Discard)
+	_, err, _ := v.ExecuteString(`println("ab".repeat(2), "ab".repeat(-(2 ** 70)), b"a" * 3, -1 * "a", "é" * 2)`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if output.String() != "abab  aaa  éé\n" {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+}
+
+func TestBinarySearchKeyCalls(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	calls := 0
+	v.Symbols().Set("negate", v.NewBuiltInFunction(v.Symbols(), func(argument ...*Value) (*Value, error) {
+		calls++
+		return v.NewInt(-argument[0].Int()), nil
+	}))
+	result, err, _ := v.ExecuteString(`
+values = []
+for i in range(0, 1024)
+    values.append(1023 - i)
+end
+(values.binary_search(-700, negate), values.binary_search(1, negate))
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	found := (<-result).GetValues()
+	if found[0].Int() != 323 || found[1].Int() != -1 {
+		t.Fatalf("expecting 323 and -1, obtained %s and %s", found[0].String(), found[1].String())
+	}
+	// Each search probes log2(1024) values and checks the one found
+	if calls > 2*11 {
+		t.Fatalf("expecting at most 22 calls of the key function, obtained %d", calls)
+	}
+}
+
+func TestValueEqualFollowsOverrides(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	result, err, _ := v.ExecuteString(`
+class Always
+    def __init__()
+        pass
+    end
+    def __equal__(other)
+        return true
+    end
+end
+a = [Always()]
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	<-result
+	a, getError := v.Symbols().Get("a")
+	if getError != nil {
+		t.Fatal(getError)
+	}
+	if !a.Equal(v.NewArray([]*Value{v.NewInt(1)})) {
+		t.Fatal("user defined __equal__ was ignored")
+	}
+	if a.Equal(v.NewArray(nil)) {
+		t.Fatal("arrays of different length should not be equal")
+	}
+	cyclic := v.NewArray(nil)
+	cyclic.SetAny([]*Value{v.NewInt(1), cyclic})
+	other := v.NewArray(nil)
+	other.SetAny([]*Value{v.NewInt(1), other})
+	if !cyclic.Equal(other) {
+		t.Fatal("equivalent cyclic arrays should be equal")
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	for source, position := range map[string]string{
+		"{\n  \"a\": 1,\n  \"b\": tru\n}": "line 3 column 11",
+		"[1, 2]]":                         "line 1 column 7",
+		"{\"a\" 1}":                       "line 1 column 6",
+		"":                                "line 1 column 1",
+	} {
+		_, loadError := v.JSONLoads([]byte(source))
+		if !errors.Is(loadError, InvalidJSON) {
+			t.Fatalf("%q: expecting InvalidJSON, obtained %v", source, loadError)
+		}
+		if !strings.Contains(loadError.Error(), position) {
+			t.Fatalf("%q: expecting %s in %v", source, position, loadError)
+		}
+	}
+	cyclic := v.NewArray(nil)
+	cyclic.SetAny([]*Value{cyclic})
+	if _, dumpError := v.JSONDumps(cyclic, ""); !errors.Is(dumpError, CyclicStructure) {
+		t.Fatalf("expecting CyclicStructure, obtained %v", dumpError)
+	}
+	shared := v.NewArray(nil)
+	if _, dumpError := v.JSONDumps(v.NewArray([]*Value{shared, shared}), ""); dumpError != nil {
+		t.Fatal(dumpError)
+	}
+	for _, script := range []string{"json.dumps([1], -1)", "json.dumps([1], 17)", "json.dumps([1], 2 ** 64)"} {
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), InvalidRange.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, InvalidRange, e)
+		}
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	clock := NewFakeClock(time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC))
+	v.SetClock(clock)
+	_, err, _ := v.ExecuteString(`
+start = time.monotonic()
+println(time.now())
+time.sleep(90)
+println(time.now(), time.monotonic() - start)
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	expected := "2024-01-01T12:00:00Z\n2024-01-01T12:01:30Z 90.000000\n"
+	if out.String() != expected {
+		t.Fatalf("expecting %q, obtained %q", expected, out.String())
+	}
+}
+
+func TestSleepHonorsStop(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	_, err, stop := v.ExecuteString(`
+time.sleep(3600)
+println("unreachable")
+`)
+	time.Sleep(50 * time.Millisecond)
+	stop <- struct{}{}
+	select {
+	case e := <-err:
+		if e != nil {
+			t.Fatal(e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sleep was not interrupted")
+	}
+}
+
+func TestHostCallSleep(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	v.SetClock(clock)
+	timeModule, _ := v.Symbols().Get("time")
+	sleep, _ := timeModule.Get("sleep")
+	// The host call is not part of any execution, the wait can't be stopped
+	result, callError := v.CallFunction(sleep, v.NewInt(90))
+	if callError != nil {
+		t.Fatal(callError)
+	}
+	if result.TypeId() != NoneId {
+		t.Fatalf("expecting none, obtained %v", result)
+	}
+	if clock.Monotonic() != 90*time.Second {
+		t.Fatalf("expecting the clock to advance 90s, obtained %s", clock.Monotonic())
+	}
+}
+
+type releaseClock struct {
+	realClock
+	released chan struct{}
+}
+
+func (clock *releaseClock) After(d time.Duration) (<-chan time.Time, func() bool) {
+	return make(chan time.Time), func() bool {
+		close(clock.released)
+		return true
+	}
+}
+
+func TestSleepInCallbackHonorsStop(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	clock := &releaseClock{released: make(chan struct{})}
+	v.SetClock(clock)
+	_, err, stop := v.ExecuteString(`
+list(map(time.sleep, [3600]))
+println("unreachable")
+`)
+	time.Sleep(50 * time.Millisecond)
+	stop <- struct{}{}
+	select {
+	case e := <-err:
+		if e != nil {
+			t.Fatal(e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sleep was not interrupted")
+	}
+	select {
+	case <-clock.released:
+	default:
+		t.Fatal("expecting the timer to be released")
+	}
+}
+
+func TestStopNestedCalls(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	_, err, stop := v.ExecuteString(`
+def spin(x)
+    while true
+        x = x + 1
+    end
+end
+list(map(spin, [1]))
+println("unreachable")
+`)
+	time.Sleep(50 * time.Millisecond)
+	stop <- struct{}{}
+	select {
+	case e := <-err:
+		if e != nil {
+			t.Fatal(e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the callback was not stopped")
+	}
+}
+
+func TestStopKeyProtocol(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	_, err, stop := v.ExecuteString(`
+class Key
+    def __init__()
+    end
+    def __hash__()
+        h = 0
+        while true
+            h = h + 1
+        end
+    end
+end
+s = Set([1, 2])
+s.add(Key())
+println("unreachable")
+`)
+	time.Sleep(50 * time.Millisecond)
+	stop <- struct{}{}
+	select {
+	case e := <-err:
+		if e != nil {
+			t.Fatal(e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the key protocol was not stopped")
+	}
+}
+
+const fileSystemScript = `
+println(exists("config.txt"), exists("reports/summary.txt"))
+def show_config()
+    f = open("config.txt")
+    defer f.close()
+    println(f.read_line())
+    for line in f
+        println("line:", line)
+    end
+    f.seek(0)
+    println(f.read(4), f.tell())
+    f.close()
+end
+show_config()
+write_file("reports/summary.txt", "total: ")
+report = open("reports/summary.txt", "a+")
+report.write("3\n")
+report.seek(0)
+println(report.read())
+report.close()
+println(read_file("reports/summary.txt"))
+println(list_dir(), list_dir("reports"))
+binary = open("config.txt", "rb")
+println(binary.read(4) == b"name")
+binary.close()
+`
+
+const fileSystemExpected = `true false
+name = plasma
+line: version = 1
+line: 
+line: debug = true
+name 4
+total: 3
+
+total: 3
+
+["config.txt", "reports"] ["summary.txt"]
+true
+`
+
+func TestConcurrentRender(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	result, err, _ := v.ExecuteString(`
+class Slow
+    def __init__()
+        pass
+    end
+    def __repr__()
+        time.sleep(0.05)
+        return "slow"
+    end
+end
+values = [Slow()]
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	<-result
+	values, _ := v.Symbols().Get("values")
+	// Both renders visit the same object at the same time, none of them is a cycle
+	var wait sync.WaitGroup
+	for worker := 0; worker < 2; worker++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			s, renderError := v.Repr(values)
+			if renderError != nil {
+				t.Error(renderError)
+			} else if s != "[slow]" {
+				t.Errorf("expecting [slow], obtained %s", s)
+			}
+		}()
+	}
+	wait.Wait()
+}
+
+func TestHostRenderCycle(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	result, err, _ := v.ExecuteString(`
+class Node
+    def __init__()
+        self.children = [self]
+    end
+    def __repr__()
+        return "Node" + self.children.__string__()
+    end
+end
+node = Node()
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	<-result
+	node, _ := v.Symbols().Get("node")
+	s, renderError := v.Repr(node)
+	if renderError != nil {
+		t.Fatal(renderError)
+	}
+	if s != "Node[...]" {
+		t.Fatalf("expecting Node[...], obtained %s", s)
+	}
+}
+
+func TestFileSystem(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "reports"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "config.txt"), []byte("name = plasma\r\nversion = 1\n\ndebug = true"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	memory := NewMemoryFS()
+	memory.WriteFile("config.txt", []byte("name = plasma\r\nversion = 1\n\ndebug = true"))
+	for name, fileSystem := range map[string]FileSystem{"dir": DirFS(root), "memory": memory} {
+		out := &bytes.Buffer{}
+		v := NewVM(nil, out, out)
+		v.SetFileSystem(fileSystem)
+		_, err, _ := v.ExecuteString(fileSystemScript)
+		if e := <-err; e != nil {
+			t.Fatalf("%s: %v", name, e)
+		}
+		if out.String() != fileSystemExpected {
+			t.Fatalf("%s: expecting %q, obtained %q", name, fileSystemExpected, out.String())
+		}
+	}
+}
+
+func TestMemoryFSMaxFileSize(t *testing.T) {
+	memory := NewMemoryFS()
+	v := NewVM(nil, io.Discard, io.Discard)
+	v.SetFileSystem(memory)
+	_, err, _ := v.ExecuteString(`
+f = open("sparse.bin", "wb")
+f.seek(10 ** 15)
+f.write(b"a")
+`)
+	if e := <-err; e == nil || !strings.Contains(e.Error(), FileTooLarge.Error()) {
+		t.Fatalf("expecting FileTooLarge, obtained %v", e)
+	}
+	memory.SetMaxFileSize(4)
+	file, openError := memory.OpenFile("small.txt", os.O_CREATE|os.O_WRONLY, 0644)
+	if openError != nil {
+		t.Fatal(openError)
+	}
+	if _, writeError := file.Write([]byte("abcd")); writeError != nil {
+		t.Fatal(writeError)
+	}
+	if _, writeError := file.Write([]byte("e")); !errors.Is(writeError, FileTooLarge) {
+		t.Fatalf("expecting FileTooLarge, obtained %v", writeError)
+	}
+	if info, _ := memory.Stat("small.txt"); info.Size() != 4 {
+		t.Fatalf("expecting 4 bytes, obtained %d", info.Size())
+	}
+}
+
+func TestFileSystemIsolation(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	if _, openError := v.openFile("config.txt", "r"); !errors.Is(openError, FileSystemDisabled) {
+		t.Fatalf("expecting FileSystemDisabled, obtained %v", openError)
+	}
+	v.SetFileSystem(ReadOnlyFS(fstest.MapFS{"data/a.txt": {Data: []byte("a")}}))
+	if _, openError := v.openFile("data/a.txt", "w"); !errors.Is(openError, fs.ErrPermission) {
+		t.Fatalf("expecting fs.ErrPermission, obtained %v", openError)
+	}
+	contents, readError := v.readFile("../../data/../data/a.txt")
+	if readError != nil {
+		t.Fatal(readError)
+	}
+	if contents.String() != "a" {
+		t.Fatalf("expecting a, obtained %s", contents.String())
+	}
+	if _, openError := v.openFile("data/a.txt", "rw"); !errors.Is(openError, InvalidFileMode) {
+		t.Fatalf("expecting InvalidFileMode, obtained %v", openError)
+	}
+	outside, sandbox := t.TempDir(), t.TempDir()
+	for _, setupError := range []error{
+		os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644),
+		os.Mkdir(filepath.Join(sandbox, "data"), 0755),
+		os.WriteFile(filepath.Join(sandbox, "data", "a.txt"), []byte("a"), 0644),
+		os.Symlink(outside, filepath.Join(sandbox, "escape")),
+		os.Symlink(filepath.Join(outside, "created.txt"), filepath.Join(sandbox, "dangling")),
+		os.Symlink(filepath.Join(sandbox, "data"), filepath.Join(sandbox, "inner")),
+	} {
+		if setupError != nil {
+			t.Fatal(setupError)
+		}
+	}
+	v.SetFileSystem(DirFS(sandbox))
+	if _, readError := v.readFile("escape/secret.txt"); !errors.Is(readError, fs.ErrPermission) {
+		t.Fatalf("expecting fs.ErrPermission, obtained %v", readError)
+	}
+	if _, listError := v.listDir("escape"); !errors.Is(listError, fs.ErrPermission) {
+		t.Fatalf("expecting fs.ErrPermission, obtained %v", listError)
+	}
+	if writeError := v.writeFile("dangling", []byte("x")); !errors.Is(writeError, fs.ErrPermission) {
+		t.Fatalf("expecting fs.ErrPermission, obtained %v", writeError)
+	}
+	if _, statError := os.Stat(filepath.Join(outside, "created.txt")); !errors.Is(statError, fs.ErrNotExist) {
+		t.Fatalf("expecting nothing created outside the sandbox, obtained %v", statError)
+	}
+	if contents, readError = v.readFile("inner/a.txt"); readError != nil || contents.String() != "a" {
+		t.Fatalf("expecting a, obtained %v %v", contents, readError)
+	}
+	if writeError := v.writeFile("data/new/../b.txt", []byte("b")); writeError != nil {
+		t.Fatal(writeError)
+	}
+}
+
+func TestStandardStreams(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	v := NewVM(strings.NewReader("first\nsecond\r\nthird\nfourth"), stdout, stderr)
+	_, err, _ := v.ExecuteString(`
+name = input("name: ")
+println(name, input())
+for line in stdin
+    stdout.write(line + ";")
+end
+println(stdin.read_line(), stdin.read_all() == "")
+stderr.write("warning\n")
+stderr.flush()
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if expected := "name: first second\nthird;fourth;none true\n"; stdout.String() != expected {
+		t.Fatalf("expecting %q, obtained %q", expected, stdout.String())
+	}
+	if stderr.String() != "warning\n" {
+		t.Fatalf("expecting warning, obtained %q", stderr.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(_ []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestStreamWriteErrors(t *testing.T) {
+	v := NewVM(nil, failingWriter{}, failingWriter{})
+	for _, script := range []string{"println(1)", "print(1)", "stdout.write(\"a\")", "stderr.write(\"a\")"} {
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), io.ErrClosedPipe.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, io.ErrClosedPipe, e)
+		}
+	}
+}
+
+type fakeOS struct {
+	env map[string]string
+}
+
+func (host *fakeOS) Args() []string { return []string{"script.pm", "--verbose"} }
+
+func (host *fakeOS) LookupEnv(key string) (string, bool) {
+	value, found := host.env[key]
+	return value, found
+}
+
+func (host *fakeOS) Setenv(key, value string) error {
+	host.env[key] = value
+	return nil
+}
+
+func (host *fakeOS) Environ() []string {
+	environ := make([]string, 0, len(host.env))
+	for key, value := range host.env {
+		environ = append(environ, key+"="+value)
+	}
+	return environ
+}
+
+func (host *fakeOS) Getwd() (string, error) { return "/work", nil }
+
+func (host *fakeOS) Run(argv []string, stdin []byte) (*ProcessResult, error) {
+	return &ProcessResult{Stdout: append([]byte(strings.Join(argv, " ")+":"), stdin...), Status: 3}, nil
+}
+
+func TestOSModule(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	v.SetOS(&fakeOS{env: map[string]string{"HOME": "/home/plasma"}})
+	_, err, _ := v.ExecuteString(`
+println(os.args(), os.cwd())
+os.env.set("MODE", "test")
+println(os.env.get("HOME"), os.env.get("MISSING"), os.env.get("MISSING", "default"))
+println(os.env.list())
+result = subprocess.run(["echo", 1], "input")
+println(result.stdout, result.status)
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	expected := "(\"script.pm\", \"--verbose\") /work\n/home/plasma none default\n{\"HOME\": \"/home/plasma\", \"MODE\": \"test\"}\necho 1:input 3\n"
+	if out.String() != expected {
+		t.Fatalf("expecting %q, obtained %q", expected, out.String())
+	}
+}
+
+func TestOSExitUnwinds(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	v.SetOS(&fakeOS{})
+	_, err, _ := v.ExecuteString(`
+END
+    println("end")
+end
+def inner()
+    defer println("inner deferred")
+    list(map(lambda x: os.exit(x), [7]))
+    println("unreachable")
+end
+def outer()
+    defer println("outer deferred")
+    inner()
+    println("unreachable")
+end
+outer()
+println("unreachable")
+`)
+	var exitError *ExitError
+	if e := <-err; !errors.As(e, &exitError) || exitError.Code != 7 {
+		t.Fatalf("expecting exit status 7, obtained %v", e)
+	}
+	if expected := "inner deferred\nouter deferred\nend\n"; out.String() != expected {
+		t.Fatalf("expecting %q, obtained %q", expected, out.String())
+	}
+}
+
+func TestWrappedExit(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	quit, convertError := v.ToValue(func(code int) error {
+		return fmt.Errorf("quitting: %w", &ExitError{Code: code})
+	})
+	if convertError != nil {
+		t.Fatal(convertError)
+	}
+	v.Symbols().Set("quit", quit)
+	_, err, _ := v.ExecuteString(`
+END
+    println("end")
+end
+def run()
+    defer println("deferred")
+    list(map(quit, [3]))
+    println("unreachable")
+end
+run()
+quit(4)
+`)
+	var exitError *ExitError
+	if e := <-err; !errors.As(e, &exitError) || exitError.Code != 3 {
+		t.Fatalf("expecting exit status 3, obtained %v", e)
+	}
+	if expected := "deferred\nend\n"; out.String() != expected {
+		t.Fatalf("expecting %q, obtained %q", expected, out.String())
+	}
+}
+
+func TestOSDisabled(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	for _, script := range []string{"os.args()", "os.exit(1)", "os.env.get(\"HOME\")", "subprocess.run([\"ls\"])"} {
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), OSDisabled.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, OSDisabled, e)
+		}
+	}
+}
+
+func TestSeedRandom(t *testing.T) {
+	const script = `println(random.int(0, 1000000), random.float(), random.sample(range(0, 100), 5))`
+	outputs := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		out := &bytes.Buffer{}
+		v := NewVM(nil, out, out)
+		v.SeedRandom(1234)
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e != nil {
+			t.Fatal(e)
+		}
+		outputs = append(outputs, out.String())
+	}
+	if outputs[0] != outputs[1] {
+		t.Fatalf("expecting the same output, obtained %q and %q", outputs[0], outputs[1])
+	}
+}
+
+func TestRandomSampleRange(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	for _, script := range []string{
+		"random.sample([1, 2], 3)",
+		"random.sample([1, 2], -1)",
+		"random.sample([1, 2], 2 ** 64 + 1)",
+		"random.sample([1, 2], -(2 ** 64) + 1)",
+	} {
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), InvalidRange.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, InvalidRange, e)
+		}
+	}
+}
+
+func TestInt64Arguments(t *testing.T) {
+	for _, script := range []string{
+		`"abc"[2 ** 64]`,
+		`(2 ** 64) | 1.5`,
+		`(2 ** 64) << 1.0`,
+		`list(enumerate([1], 2 ** 64))`,
+		`take([1], 2 ** 64)`,
+		`skip([1], -(2 ** 64))`,
+	} {
+		v := NewVM(nil, io.Discard, io.Discard)
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), IntegerTooLarge.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, IntegerTooLarge, e)
+		}
+	}
+	var output bytes.Buffer
+	v := NewVM(nil, &output, io.Discard)
+	_, err, _ := v.ExecuteString(`println(2 ** 64 in "abc", 97 in "abc", "abc"[1], 6 | 0.0)`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if output.String() != "false true b 6\n" {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+}
+
+func TestRandomArgumentsRange(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	for script, expect := range map[string]error{
+		"random.bytes(-1)":                    InvalidRange,
+		"random.bytes(2 ** 64)":               TextTooLong,
+		"random.bytes(2 ** 40)":               TextTooLong,
+		"random.Random(1).bytes(-(2 ** 64))":  InvalidRange,
+		"random.Random(1).bytes(2 ** 64 + 1)": TextTooLong,
+		"random.Random(2 ** 63)":              IntegerTooLarge,
+		"random.Random(-(2 ** 63) - 1)":       IntegerTooLarge,
+	} {
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), expect.Error()) {
+			t.Fatalf("%s: expecting %v, obtained %v", script, expect, e)
+		}
+	}
+	_, err, _ := v.ExecuteString("random.Random(-(2 ** 63)).bytes(4)")
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+}
+
+type reflectionConfig struct {
+	Name    string            `plasma:"name"`
+	Ports   []uint16          `plasma:"ports"`
+	Labels  map[string]string `plasma:"labels"`
+	Timeout time.Duration     `plasma:"timeout"`
+	Parent  *reflectionConfig `plasma:"parent"`
+	secret  string
+	Ignored bool `plasma:"-"`
+}
+
+func TestToValue(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	var stored reflectionConfig
+	host := map[string]any{
+		"config": reflectionConfig{
+			Name:    "server",
+			Ports:   []uint16{80, 443},
+			Labels:  map[string]string{"b": "2", "a": "1"},
+			Timeout: 3 * time.Second,
+			secret:  "hidden",
+		},
+		"divide": func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a / b, nil
+		},
+		"store": func(value *Value) error {
+			return value.ToGo(&stored)
+		},
+		"join": func(separator string, parts ...string) string {
+			return strings.Join(parts, separator)
+		},
+	}
+	for name, value := range host {
+		converted, convertError := v.ToValue(value)
+		if convertError != nil {
+			t.Fatal(convertError)
+		}
+		v.Load(name, func(*Plasma) *Value { return converted })
+	}
+	_, err, _ := v.ExecuteString(`
+println(config.name, config.ports, config.labels, config.timeout.seconds(), config.parent)
+println(divide(7, 2), join("-", "a", "b", "c"))
+config.name = "copy"
+config.ports.append(8080)
+config.parent = {"name": "root", "ports": (1,)}
+store(config)
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	const expect = "server [80, 443] {\"a\": \"1\", \"b\": \"2\"} 3.000000 none\n3 a-b-c\n"
+	if out.String() != expect {
+		t.Fatalf("expecting %q, obtained %q", expect, out.String())
+	}
+	if stored.Name != "copy" || len(stored.Ports) != 3 || stored.Ports[2] != 8080 || stored.Timeout != 3*time.Second {
+		t.Fatalf("unexpected conversion %+v", stored)
+	}
+	if stored.Parent == nil || stored.Parent.Name != "root" || stored.Parent.Ports[0] != 1 {
+		t.Fatalf("unexpected parent %+v", stored.Parent)
+	}
+	_, err, _ = v.ExecuteString(`divide(1, 0)`)
+	if e := <-err; e == nil || !strings.Contains(e.Error(), "division by zero") {
+		t.Fatalf("expecting the Go error, obtained %v", e)
+	}
+	_, err, _ = v.ExecuteString(`store({"ports": [70000]})`)
+	if e := <-err; e == nil || !strings.Contains(e.Error(), NotConvertible.Error()) {
+		t.Fatalf("expecting a conversion error, obtained %v", e)
+	}
+	cyclic := &reflectionConfig{}
+	cyclic.Parent = cyclic
+	if _, convertError := v.ToValue(cyclic); !errors.Is(convertError, CyclicStructure) {
+		t.Fatalf("expecting %v, obtained %v", CyclicStructure, convertError)
+	}
+}
+
+func TestToGoFunction(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	var callback *Value
+	register, _ := v.ToValue(func(value *Value) { callback = value })
+	v.Load("register", func(*Plasma) *Value { return register })
+	_, err, _ := v.ExecuteString(`
+def add(a, b)
+    return a + b
+end
+register(add)
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	var add func(a, b float64) (float64, error)
+	if convertError := callback.ToGo(&add); convertError != nil {
+		t.Fatal(convertError)
+	}
+	if sum, callError := add(1.5, 2); callError != nil || sum != 3.5 {
+		t.Fatalf("expecting 3.5, obtained %v %v", sum, callError)
+	}
+	var natural any
+	value, _ := v.ToValue([]any{int64(1), "two", map[string]int{"three": 3}})
+	if convertError := value.ToGo(&natural); convertError != nil {
+		t.Fatal(convertError)
+	}
+	expect := []any{int64(1), "two", map[any]any{"three": int64(3)}}
+	if fmt.Sprint(natural) != fmt.Sprint(expect) {
+		t.Fatalf("expecting %v, obtained %v", expect, natural)
+	}
+}
+
+type builderCounter struct {
+	step  int64
+	count int64
+}
+
+func counterClass(v *Plasma) *Value {
+	return v.NewClassBuilder("Counter").
+		Constructor(func(argument ...*Value) (any, error) {
+			return &builderCounter{step: argument[0].Int()}, nil
+		}).
+		Method("increment", func(self *Value, argument ...*Value) (*Value, error) {
+			counter := self.GetAny().(*builderCounter)
+			counter.count += counter.step
+			return v.NewInt(counter.count), nil
+		}).
+		Method(magic_functions.String, func(self *Value, argument ...*Value) (*Value, error) {
+			return v.NewString([]byte(fmt.Sprintf("Counter(%d)", self.GetAny().(*builderCounter).count))), nil
+		}).
+		Property("count",
+			func(self *Value) (*Value, error) {
+				return v.NewInt(self.GetAny().(*builderCounter).count), nil
+			},
+			func(self, value *Value) error {
+				self.GetAny().(*builderCounter).count = value.Int()
+				return nil
+			},
+		).
+		Property("step",
+			func(self *Value) (*Value, error) {
+				return v.NewInt(self.GetAny().(*builderCounter).step), nil
+			},
+			nil,
+		).
+		Build()
+}
+
+func TestClassBuilder(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	class := counterClass(v)
+	v.Load("Counter", func(*Plasma) *Value { return class })
+	_, err, _ := v.ExecuteString(`
+c = Counter(2)
+c.increment()
+c.increment()
+println(c, c.count, c.step)
+c.count = 10
+println(c.increment())
+class Double(Counter)
+    def __init__()
+        Counter.__init__(self, 3)
+    end
+    def increment()
+        Counter.increment(self)
+        return Counter.increment(self)
+    end
+end
+d = Double()
+println(d.increment(), d.count, d.__class__() == Double)
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	const expect = "Counter(4) 4 2\n12\n6 6 true\n"
+	if out.String() != expect {
+		t.Fatalf("expecting %q, obtained %q", expect, out.String())
+	}
+	for script, expectError := range map[string]error{
+		"Counter(1).step = 5": ReadOnlyProperty,
+		`class Empty(Counter)
+    def __init__()
+    end
+end
+Empty().increment()`: ObjectNotCreated,
+	} {
+		_, err, _ = v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), expectError.Error()) {
+			t.Fatalf("expecting %v, obtained %v", expectError, e)
+		}
+	}
+}
+
+func TestSignatures(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	rangeFunction, getError := v.Symbols().Get("range")
+	if getError != nil {
+		t.Fatal(getError)
+	}
+	signature, ok := rangeFunction.Signature()
+	if !ok || signature.String() != "range(start, end, [step])" {
+		t.Fatalf("expecting range(start, end, [step]), obtained %v %v", signature.String(), ok)
+	}
+	var argumentError *ArgumentError
+	if _, callError := v.CallFunction(rangeFunction); !errors.As(callError, &argumentError) || argumentError.Received != 0 {
+		t.Fatalf("expecting ArgumentError, obtained %v", callError)
+	}
+	var typeError *TypeError
+	_, callError := v.CallFunction(rangeFunction, v.NewInt(0), v.NewString([]byte("10")))
+	if !errors.As(callError, &typeError) || typeError.Parameter != "end" || typeError.Received != StringId {
+		t.Fatalf("expecting TypeError, obtained %v", callError)
+	}
+	wrapped, _ := v.ToValue(func(a int64, rest ...string) {})
+	if signature, _ = wrapped.Signature(); !strings.HasSuffix(signature.String(), "(int64, string...)") {
+		t.Fatalf("expecting (int64, string...), obtained %s", signature.String())
+	}
+	for script, expect := range map[string]string{
+		"range()":        "argument error: range(start, end, [step]) expects 2 to 3 arguments but received 0",
+		"input(1, 2)":    "argument error: input([prompt]) expects 0 to 1 arguments but received 2",
+		`"a b".split(1)`: "type error: argument separator of split must be String or Bytes, received Int",
+		"Hash([1])":      "type error: argument hash of Hash must be Hash, received Array",
+		"Set(1)":         "type error: argument iterable of Set must be String or Bytes or Array or Tuple or Hash or Set, received Int",
+		"[1].__len__(2)": "argument error: __len__() expects 0 arguments but received 1",
+		"sorted()":       "argument error: sorted(iterable, [key], [reverse]) expects 1 to 3 arguments but received 0",
+		"map(println)":   "argument error: map(function, iterable, iterables...) expects at least 2 arguments but received 1",
+	} {
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), expect) {
+			t.Fatalf("%s: expecting %s, obtained %v", script, expect, e)
+		}
+	}
+}
+
+func TestScopes(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewVM(nil, out, out)
+	first, second := v.NewScope(), v.NewScope()
+	first.Set("tenant", v.NewString([]byte("first")))
+	bytecode, compileError := compiler.Compile("counter = 1\nprintln(tenant, counter)")
+	if compileError != nil {
+		t.Fatal(compileError)
+	}
+	_, err, _ := v.ExecuteIn(first, bytecode)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if out.String() != "first 1\n" {
+		t.Fatalf("expecting %q, obtained %q", "first 1\n", out.String())
+	}
+	for _, symbols := range []*Symbols{second, v.Symbols()} {
+		if _, getError := symbols.Get("counter"); !errors.Is(getError, SymbolNotFoundError) {
+			t.Fatalf("expecting counter to stay in its scope, obtained %v", getError)
+		}
+	}
+	_, err, _ = v.ExecuteIn(second, bytecode)
+	if e := <-err; e == nil {
+		t.Fatal("expecting tenant to be undefined in the second scope")
+	}
+}
+
+func TestPool(t *testing.T) {
+	created := int64(0)
+	pool := NewPool(2, func() *Plasma {
+		atomic.AddInt64(&created, 1)
+		v := NewVM(nil, io.Discard, io.Discard)
+		// leaked only becomes true when an execution writes its globals in the root symbols
+		v.Symbols().Set("leaked", v.False())
+		return v
+	})
+	bytecode, compileError := compiler.Compile(`
+if leaked
+    println("leaked")
+end
+leaked = true
+println(tenant)
+`)
+	if compileError != nil {
+		t.Fatal(compileError)
+	}
+	for index := 0; index < 4; index++ {
+		var output bytes.Buffer
+		_, executeError := pool.Execute(gocontext.Background(), bytecode, func(plasma *Plasma, scope *Symbols) {
+			plasma.Stdout = &output
+			scope.Set("tenant", plasma.NewInt(int64(index)))
+		})
+		if executeError != nil {
+			t.Fatal(executeError)
+		}
+		if expect := fmt.Sprintf("%d\n", index); output.String() != expect {
+			t.Fatalf("expecting %q, obtained %q", expect, output.String())
+		}
+	}
+	if atomic.LoadInt64(&created) != 1 {
+		t.Fatalf("expecting sequential executions to reuse the VM, created %d", created)
+	}
+	var wg sync.WaitGroup
+	outputs := make([]bytes.Buffer, 8)
+	for index := range outputs {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			_, executeError := pool.Execute(gocontext.Background(), bytecode, func(plasma *Plasma, scope *Symbols) {
+				plasma.Stdout = &outputs[index]
+				scope.Set("tenant", plasma.NewInt(int64(index)))
+			})
+			if executeError != nil {
+				t.Error(executeError)
+			}
+		}(index)
+	}
+	wg.Wait()
+	for index := range outputs {
+		if expect := fmt.Sprintf("%d\n", index); outputs[index].String() != expect {
+			t.Fatalf("expecting %q, obtained %q", expect, outputs[index].String())
+		}
+	}
+	reused := pool.Get()
+	if reused.Stdout != io.Discard {
+		t.Fatal("expecting the standard streams to be restored")
+	}
+}
+
+func TestPoolContext(t *testing.T) {
+	pool := NewPool(1, func() *Plasma {
+		return NewVM(nil, io.Discard, io.Discard)
+	})
+	spin, compileError := compiler.Compile(`
+while true
+    pass
+end
+`)
+	if compileError != nil {
+		t.Fatal(compileError)
+	}
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, executeError := pool.Execute(ctx, spin, nil); !errors.Is(executeError, gocontext.DeadlineExceeded) {
+		t.Fatalf("expecting the deadline error, obtained %v", executeError)
+	}
+	answer, compileError := compiler.Compile("40 + 2")
+	if compileError != nil {
+		t.Fatal(compileError)
+	}
+	result, executeError := pool.Execute(gocontext.Background(), answer, nil)
+	if executeError != nil {
+		t.Fatal(executeError)
+	}
+	if result.Int() != 42 {
+		t.Fatalf("expecting 42, obtained %s", result.String())
+	}
+}
+
+func TestFrozenBuiltIns(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	for _, script := range []string{
+		"math.pi = 0",
+		"delete math.pi",
+		"String.join = 1",
+		"println.name = 1",
+		"os.env.get = 1",
+		"math.__repr__.x = 1",
+		"none.x = 1",
+	} {
+		_, err, _ := v.ExecuteString(script)
+		if e := <-err; e == nil || !strings.Contains(e.Error(), FrozenValue.Error()) {
+			t.Fatalf("%s: expecting a frozen value error, obtained %v", script, e)
+		}
+	}
+	var output bytes.Buffer
+	v.Stdout = &output
+	bytecode, compileError := compiler.Compile(`
+class Point
+    def __init__()
+        self.x = 1
+    end
+end
+p = Point()
+p.x = 2
+println(math.pi, p.x)
+`)
+	if compileError != nil {
+		t.Fatal(compileError)
+	}
+	_, err, _ := v.ExecuteIn(v.NewScope(), bytecode)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	if output.String() != "3.141593 2\n" {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+}
+
+type recordingTracer struct {
+	steps  int
+	events []string
+}
+
+func (tracer *recordingTracer) Step(opcode byte, offset int64) {
+	tracer.steps++
+}
+
+func (tracer *recordingTracer) Call(function *Value, argument []*Value) {
+	tracer.events = append(tracer.events, fmt.Sprint("call ", traceValue(function), " ", len(argument)))
+}
+
+func (tracer *recordingTracer) Return(function, result *Value) {
+	tracer.events = append(tracer.events, fmt.Sprint("return ", traceValue(function), " ", traceValue(result)))
+}
+
+func (tracer *recordingTracer) GetSymbol(symbol string, value *Value) {
+	tracer.events = append(tracer.events, "get "+symbol)
+}
+
+func (tracer *recordingTracer) SetSymbol(symbol string, value *Value) {
+	tracer.events = append(tracer.events, fmt.Sprint("set ", symbol, " ", traceValue(value)))
+}
+
+func (tracer *recordingTracer) Error(err error) {
+	tracer.events = append(tracer.events, "error "+err.Error())
+}
+
+func TestTracer(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	tracer := &recordingTracer{}
+	v.SetTracer(tracer)
+	_, err, _ := v.ExecuteString(`
+def twice(value)
+    return value * 2
+end
+result = twice(21)
+println(result)
+missing()
+`)
+	if e := <-err; e == nil {
+		t.Fatal("expecting missing to be undefined")
+	}
+	expect := []string{
+		"set twice <Function>",
+		"get twice",
+		"call <Function> 1",
+		"return <Function> 42",
+		"set result 42",
+		"get println",
+		"call println 1",
+		"return println <nil>",
+		"error symbol not found",
+	}
+	next := 0
+	for _, event := range tracer.events {
+		if next < len(expect) && event == expect[next] {
+			next++
+		}
+	}
+	if next != len(expect) || tracer.steps == 0 {
+		t.Fatalf("expecting the events %v in order, obtained %v", expect, tracer.events)
+	}
+	out := &bytes.Buffer{}
+	jsonTracer := NewJSONTracer(out)
+	v.SetTracer(jsonTracer)
+	_, err, _ = v.ExecuteString(`println("hello", 1.5, none)`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	v.SetTracer(nil)
+	if jsonTracer.Err() != nil {
+		t.Fatal(jsonTracer.Err())
+	}
+	var calls []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event map[string]any
+		if decodeError := json.Unmarshal([]byte(line), &event); decodeError != nil {
+			t.Fatalf("%q: %v", line, decodeError)
+		}
+		if event["event"] == "call" {
+			calls = append(calls, event)
+		}
+	}
+	if len(calls) != 1 || fmt.Sprint(calls[0]["arguments"]) != "[hello 1.5 <nil>]" {
+		t.Fatalf("expecting the println call, obtained %v", calls)
+	}
+}
+
+func TestHashConcurrentSet(t *testing.T) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	// pause lets the other workers run while __equal__ compares against the bucket they all share
+	v.Symbols().Set("pause", v.NewBuiltInFunction(v.Symbols(), func(argument ...*Value) (*Value, error) {
+		runtime.Gosched()
+		return v.None(), nil
+	}))
+	result, err, _ := v.ExecuteString(`
+class Key
+    def __init__(name)
+        self.name = name
+    end
+    def __hash__()
+        return 1
+    end
+    def __equal__(other)
+        pause()
+        return self.name == other.name
+    end
+end
+sentinel = Key("sentinel")
+keys = []
+for i in range(0, 8)
+    keys.append(Key("key"))
+end
+`)
+	if e := <-err; e != nil {
+		t.Fatal(e)
+	}
+	<-result
+	sentinel, _ := v.Symbols().Get("sentinel")
+	keys, _ := v.Symbols().Get("keys")
+	for round := 0; round < 50; round++ {
+		hash := v.NewInternalHash()
+		if setError := hash.Set(sentinel, v.None()); setError != nil {
+			t.Fatal(setError)
+		}
+		var wait sync.WaitGroup
+		for _, key := range keys.GetValues() {
+			wait.Add(1)
+			go func(key *Value) {
+				defer wait.Done()
+				if setError := hash.Set(key, key); setError != nil {
+					t.Error(setError)
+				}
+			}(key)
+		}
+		wait.Wait()
+		if size := hash.Size(); size != 2 {
+			t.Fatalf("expecting 2 entries, obtained %d", size)
+		}
+	}
+}
+
+func benchmarkHashKeys(v *Plasma) []*Value {
+	keys := make([]*Value, 0, 1000)
+	for i := 0; i < 500; i++ {
+		keys = append(keys, v.NewString([]byte(fmt.Sprintf("key-%d", i))))
+		keys = append(keys, v.NewInt(int64(i)))
+	}
+	return keys
+}
+
+func BenchmarkHashSet(b *testing.B) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	keys := benchmarkHashKeys(v)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash := v.NewInternalHash()
+		for _, key := range keys {
+			_ = hash.Set(key, key)
+		}
+	}
+}
+
+func BenchmarkHashGet(b *testing.B) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	keys := benchmarkHashKeys(v)
+	hash := v.NewInternalHash()
+	for _, key := range keys {
+		_ = hash.Set(key, key)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			_, _ = hash.Get(key)
+		}
+	}
+}
+
+func BenchmarkSelector(b *testing.B) {
+	v := NewVM(nil, io.Discard, io.Discard)
+	result, err, _ := v.ExecuteString(`
+class Point
+    def __init__()
+        self.x = 1
+    end
+end
+p = Point()
+`)
+	if e := <-err; e != nil {
+		b.Fatal(e)
+	}
+	<-result
+	p, _ := v.Symbols().Get("p")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = p.Get("x")
+	}
+}